@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+const aliasesSection = "aliases"
+
+func runAliasCommand(appConfig *config.AppConfig, moduleInfos map[string]*module.ModuleInfo, cmdArgs *config.ProgramArguments) error {
+	command := cmdArgs.Alias
+	switch {
+	case command.Suggest != nil:
+		return suggestAliases(appConfig, moduleInfos, command.Suggest)
+	case command.Add != nil:
+		return addAlias(appConfig, moduleInfos, command.Add)
+	case command.Remove != nil:
+		return removeAlias(appConfig, command.Remove)
+	}
+	return cmdArgs.WriteHelp("alias")
+}
+
+func suggestAliases(appConfig *config.AppConfig, moduleInfos map[string]*module.ModuleInfo, command *config.AliasSuggestCommand) error {
+	names := make([]string, 0, len(moduleInfos))
+	for name, moduleInfo := range moduleInfos {
+		if len(moduleInfo.Aliases) == 0 {
+			names = append(names, name)
+		}
+	}
+	suggestions := module.SuggestAliases(names, appConfig.Aliases)
+	if len(suggestions) == 0 {
+		fmt.Println("every registry module already has an alias")
+		return nil
+	}
+
+	aliases := make([]string, 0, len(suggestions))
+	for alias := range suggestions {
+		aliases = append(aliases, alias)
+	}
+	slices.Sort(aliases)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ALIAS\tMODULE")
+	for _, alias := range aliases {
+		fmt.Fprintf(writer, "%s\t%s\n", alias, suggestions[alias])
+	}
+	err := writer.Flush()
+	if err != nil || !command.Apply {
+		return err
+	}
+
+	file, err := editedFile(appConfig, command.File, "")
+	if err != nil {
+		return err
+	}
+	err = config.SetMappingValues(file, aliasesSection, suggestions)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s OK %s %d aliases written to %s\n", config.OkColor, config.NoColor, len(suggestions), file)
+	return nil
+}
+
+func addAlias(appConfig *config.AppConfig, moduleInfos map[string]*module.ModuleInfo, command *config.AliasAddCommand) error {
+	if moduleInfos[command.Module] == nil {
+		return fmt.Errorf("module %s is not in the module registry", command.Module)
+	}
+	if existing, ok := appConfig.Aliases[command.Alias]; ok && !command.Force {
+		return fmt.Errorf("alias %s already points to %s, use --force to replace it", command.Alias, existing)
+	}
+	if moduleInfos[command.Alias] != nil && command.Alias != command.Module {
+		return fmt.Errorf("alias %s would hide the module with the same name", command.Alias)
+	}
+	file, err := editedFile(appConfig, command.File, aliasesSection+"."+command.Alias)
+	if err != nil {
+		return err
+	}
+	err = config.SetMappingValues(file, aliasesSection, map[string]string{command.Alias: command.Module})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s OK %s alias %s -> %s written to %s\n", config.OkColor, config.NoColor, command.Alias, command.Module, file)
+	return nil
+}
+
+func removeAlias(appConfig *config.AppConfig, command *config.AliasRemoveCommand) error {
+	if _, ok := appConfig.Aliases[command.Alias]; !ok && command.File == "" {
+		return fmt.Errorf("alias %s is not defined", command.Alias)
+	}
+	file, err := editedFile(appConfig, command.File, aliasesSection+"."+command.Alias)
+	if err != nil {
+		return err
+	}
+	err = config.RemoveMappingKey(file, aliasesSection, command.Alias)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s OK %s alias %s removed from %s\n", config.OkColor, config.NoColor, command.Alias, file)
+	return nil
+}
+
+func editedFile(appConfig *config.AppConfig, explicit string, path string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	file := appConfig.EditableFile(path)
+	if file == "" {
+		return "", errors.New("no configuration file to edit, create one with `wcb init`")
+	}
+	return file, nil
+}
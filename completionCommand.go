@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+func runCompletionCommand(command *config.CompletionCommand) error {
+	switch command.Shell {
+	case "bash":
+		fmt.Print(bashCompletion())
+	case "zsh":
+		fmt.Print("autoload -U +X bashcompinit && bashcompinit\n" + bashCompletion())
+	case "fish":
+		fmt.Print(fishCompletion())
+	default:
+		return fmt.Errorf("shell %q is not supported, use bash, zsh or fish", command.Shell)
+	}
+	return nil
+}
+
+func runCompleteCommand(cmdArgs *config.ProgramArguments) {
+	appConfig, err := config.CreateAppConfig(loadOptions(cmdArgs))
+	if err != nil {
+		return
+	}
+	command := cmdArgs.Complete
+	var candidates []string
+	switch command.Kind {
+	case config.CompleteModule:
+		moduleInfos, err := module.CalculateModuleInfo(appConfig)
+		if err != nil {
+			return
+		}
+		candidates = module.ModuleCandidates(appConfig, moduleInfos, command.Prefix)
+	case config.CompleteCustom:
+		name, moduleId, found := strings.Cut(command.Prefix, config.CustomModuleSeparator)
+		if !found || strings.Contains(moduleId, config.CustomArgumentsSeparator) {
+			candidates = config.SortedKeys(appConfig.Commands.Custom)
+			break
+		}
+		moduleInfos, err := module.CalculateModuleInfo(appConfig)
+		if err != nil {
+			return
+		}
+		for _, id := range module.ModuleCandidates(appConfig, moduleInfos, moduleId) {
+			if !strings.HasPrefix(id, config.ModuleSetPrefix) {
+				candidates = append(candidates, name+config.CustomModuleSeparator+id)
+			}
+		}
+	case config.CompleteProfile:
+		candidates = config.SortedKeys(appConfig.Profiles)
+	}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, command.Prefix) {
+			fmt.Println(candidate)
+		}
+	}
+}
+
+func bashCompletion() string {
+	completions := config.Completions()
+	kinds := make(map[string][]string)
+	multiple := make([]string, 0)
+	subcommands := make([]string, 0)
+	builder := strings.Builder{}
+	builder.WriteString("# bash completion for " + config.ProgramName + ", generated by `" + config.ProgramName + " completion bash`\n\n")
+
+	builder.WriteString("_wcb_flags() {\n    case \"$1\" in\n")
+	for _, completion := range completions {
+		names := make([]string, 0)
+		for _, flag := range completion.Flags {
+			names = append(names, flag.Names...)
+			kind := flag.Kind
+			if !flag.Switch && kind == "" {
+				kind = "value"
+			}
+			if kind != "" && !slices.Contains(kinds[kind], flag.Names[len(flag.Names)-1]) {
+				kinds[kind] = append(kinds[kind], flag.Names...)
+			}
+			if flag.Multiple && !slices.Contains(multiple, flag.Names[len(flag.Names)-1]) {
+				multiple = append(multiple, flag.Names...)
+			}
+		}
+		if completion.Name == "" {
+			fmt.Fprintf(&builder, "        global) echo \"%s\" ;;\n", strings.Join(names, " "))
+			continue
+		}
+		subcommands = append(subcommands, completion.Name)
+		fmt.Fprintf(&builder, "        %s) echo \"%s\" ;;\n", completion.Name, strings.Join(append(completion.Subcommands, names...), " "))
+	}
+	builder.WriteString("    esac\n}\n\n")
+
+	builder.WriteString("_wcb_kind() {\n    case \"$1\" in\n")
+	for _, kind := range config.SortedKeys(kinds) {
+		fmt.Fprintf(&builder, "        %s) echo %s ;;\n", strings.Join(kinds[kind], "|"), kind)
+	}
+	builder.WriteString("    esac\n}\n\n")
+
+	fmt.Fprintf(&builder, `_wcb() {
+    local cur prev words cword
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        _get_comp_words_by_ref -n =: cur prev words cword
+    else
+        cur="${COMP_WORDS[COMP_CWORD]}"
+        prev="${COMP_WORDS[COMP_CWORD-1]}"
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+    fi
+
+    local command="" globals=() i word
+    for ((i = 1; i < cword; i++)); do
+        word="${words[i]}"
+        case "$word" in
+            --config|--profile)
+                globals+=("$word" "${words[i+1]}")
+                ((i++)) ;;
+            --config=*|--profile=*)
+                globals+=("$word") ;;
+            -*)
+                [[ -z "$command" ]] && command=run ;;
+            *)
+                if [[ -z "$command" ]]; then
+                    case " %s " in
+                        *" $word "*) command="$word" ;;
+                        *) command=run ;;
+                    esac
+                fi ;;
+        esac
+    done
+
+    local kind
+    kind=$(_wcb_kind "$prev")
+    if [[ -z "$kind" && "$cur" != -* ]]; then
+        for ((i = cword - 1; i > 0; i--)); do
+            word="${words[i]}"
+            if [[ "$word" == -* ]]; then
+                case " %s " in
+                    *" $word "*) kind=$(_wcb_kind "$word") ;;
+                esac
+                break
+            fi
+        done
+    fi
+
+    case "$kind" in
+        file)
+            COMPREPLY=($(compgen -f -- "$cur")) ;;
+        dir)
+            COMPREPLY=($(compgen -d -- "$cur")) ;;
+        value)
+            COMPREPLY=() ;;
+        ?*)
+            COMPREPLY=($(compgen -W "$(%s "${globals[@]}" complete "$kind" "$cur" 2>/dev/null)" -- "$cur"))
+            [[ "$cur" == *_* ]] && compopt -o nospace 2>/dev/null ;;
+        *)
+            local candidates
+            candidates="$(_wcb_flags global) $(_wcb_flags "${command:-run}")"
+            [[ -z "$command" ]] && candidates="$candidates %s"
+            COMPREPLY=($(compgen -W "$candidates" -- "$cur")) ;;
+    esac
+    declare -F __ltrim_colon_completions >/dev/null && __ltrim_colon_completions "$cur"
+}
+
+complete -F _wcb %s
+`, strings.Join(subcommands, " "), strings.Join(multiple, " "), config.ProgramName, strings.Join(subcommands, " "), config.ProgramName)
+	return builder.String()
+}
+
+func fishCompletion() string {
+	completions := config.Completions()
+	subcommands := make([]string, 0)
+	for _, completion := range completions[1:] {
+		subcommands = append(subcommands, completion.Name)
+	}
+	builder := strings.Builder{}
+	builder.WriteString("# fish completion for " + config.ProgramName + ", generated by `" + config.ProgramName + " completion fish`\n\n")
+
+	builder.WriteString("function __wcb_kind\n    string match -q -- '-*' (commandline -ct); and return 1\n    set -l tokens (commandline -opc)\n    set -l prev $tokens[-1]\n    switch $prev\n")
+	multiple := make(map[string][]string)
+	single := make(map[string][]string)
+	for _, completion := range completions {
+		for _, flag := range completion.Flags {
+			if flag.Kind == "" {
+				continue
+			}
+			target := single
+			if flag.Multiple {
+				target = multiple
+			}
+			if !slices.Contains(target[flag.Kind], flag.Names[len(flag.Names)-1]) {
+				target[flag.Kind] = append(target[flag.Kind], flag.Names...)
+			}
+		}
+	}
+	for _, kind := range config.SortedKeys(single) {
+		fmt.Fprintf(&builder, "        case %s\n            echo %s\n            return\n", strings.Join(append(single[kind], multiple[kind]...), " "), kind)
+	}
+	for _, kind := range config.SortedKeys(multiple) {
+		if _, ok := single[kind]; !ok {
+			fmt.Fprintf(&builder, "        case %s\n            echo %s\n            return\n", strings.Join(multiple[kind], " "), kind)
+		}
+	}
+	builder.WriteString("    end\n    for token in $tokens[-1..2]\n        switch $token\n")
+	for _, kind := range config.SortedKeys(multiple) {
+		fmt.Fprintf(&builder, "            case %s\n                echo %s\n                return\n", strings.Join(multiple[kind], " "), kind)
+	}
+	builder.WriteString("            case '-*'\n                return 1\n        end\n    end\n    return 1\nend\n\n")
+
+	fmt.Fprintf(&builder, `function __wcb_candidates
+    set -l current (commandline -ct)
+    switch (__wcb_kind)
+        case file
+            __fish_complete_path $current
+        case dir
+            __fish_complete_directories $current
+        case '*'
+            set -l globals
+            set -l tokens (commandline -opc)
+            for i in (seq 2 (count $tokens))
+                switch $tokens[$i]
+                    case --config --profile
+                        set -a globals $tokens[$i] $tokens[(math $i + 1)]
+                    case '--config=*' '--profile=*'
+                        set -a globals $tokens[$i]
+                end
+            end
+            %s $globals complete (__wcb_kind) $current 2>/dev/null
+    end
+end
+
+complete -c %s -f
+complete -c %s -n '__wcb_kind >/dev/null' -a '(__wcb_candidates)'
+`, config.ProgramName, config.ProgramName, config.ProgramName)
+
+	others := strings.Join(subcommands, " ")
+	fmt.Fprintf(&builder, "complete -c %s -n 'not __wcb_kind >/dev/null; and __fish_use_subcommand' -a '%s'\n", config.ProgramName, others)
+	for _, completion := range completions {
+		condition := "not __wcb_kind >/dev/null"
+		switch completion.Name {
+		case "":
+		case config.RunSubcommand:
+			condition += "; and not __fish_seen_subcommand_from " + strings.Join(slices.DeleteFunc(slices.Clone(subcommands), func(name string) bool { return name == config.RunSubcommand }), " ")
+		default:
+			condition += "; and __fish_seen_subcommand_from " + completion.Name
+			if len(completion.Subcommands) > 0 {
+				fmt.Fprintf(&builder, "complete -c %s -n '%s' -a '%s'\n", config.ProgramName, condition, strings.Join(completion.Subcommands, " "))
+			}
+		}
+		for _, flag := range completion.Flags {
+			options := make([]string, 0, len(flag.Names))
+			for _, name := range flag.Names {
+				if long, ok := strings.CutPrefix(name, "--"); ok {
+					options = append(options, "-l "+long)
+				} else {
+					options = append(options, "-s "+strings.TrimPrefix(name, "-"))
+				}
+			}
+			fmt.Fprintf(&builder, "complete -c %s -n '%s' %s -d %s\n", config.ProgramName, condition, strings.Join(options, " "), fishQuote(flag.Help))
+		}
+	}
+	return builder.String()
+}
+
+func fishQuote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
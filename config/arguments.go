@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/alexflint/go-arg"
+)
+
+const ProgramName = "wcb"
+const RunSubcommand = "run"
+
+const RestartAuto = "auto"
+const restartAutoFlag = "--restart-auto"
+
+var globalValueFlags = []string{"--config", "--profile"}
+
+func ParseCmdArgs() *ProgramArguments {
+	args := &ProgramArguments{}
+	parser, err := arg.NewParser(arg.Config{Program: ProgramName}, args)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	args.parser = parser
+
+	err = parser.Parse(withDefaultSubcommand(withRestartMode(os.Args[1:])))
+	switch {
+	case errors.Is(err, arg.ErrHelp):
+		parser.WriteHelp(os.Stdout)
+		os.Exit(0)
+	case err != nil:
+		parser.WriteUsage(os.Stderr)
+		fmt.Fprintln(os.Stderr, "error:", err.Error())
+		os.Exit(1)
+	}
+	if args.Run == nil {
+		args.Run = &RunCommand{}
+	}
+	return args
+}
+
+func withDefaultSubcommand(args []string) []string {
+	for i := 0; i < len(args); i++ {
+		current := args[i]
+		switch {
+		case slices.Contains(globalValueFlags, current):
+			i++
+			continue
+		case strings.HasPrefix(current, "--") && slices.Contains(globalValueFlags, strings.SplitN(current, "=", 2)[0]):
+			continue
+		case current == "-h" || current == "--help":
+			return args
+		case !strings.HasPrefix(current, "-") && slices.Contains(subcommandNames(), current):
+			return args
+		}
+		break
+	}
+	return append([]string{RunSubcommand}, args...)
+}
+
+func withRestartMode(args []string) []string {
+	result := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		current := args[i]
+		switch {
+		case current == "-r="+RestartAuto || current == "--restart="+RestartAuto:
+			result = append(result, restartAutoFlag)
+		case (current == "-r" || current == "--restart") && i+1 < len(args) && args[i+1] == RestartAuto:
+			result = append(result, restartAutoFlag)
+			i++
+		default:
+			result = append(result, current)
+		}
+	}
+	return result
+}
+
+func subcommandNames() []string {
+	return commandCompletion("", reflect.TypeOf(ProgramArguments{})).Subcommands
+}
+
+type ProgramArguments struct {
+	Config         string                 `arg:"--config" help:"Configuration file applied on top of the discovered ones" complete:"file"`
+	Profile        string                 `arg:"--profile" help:"Configuration profile to use, defaults to WCB_PROFILE or profile from configuration" complete:"profile"`
+	Run            *RunCommand            `arg:"subcommand:run" help:"Execute builds, tests and commands (default, e.g. wcb -b mpml_sc -u mpml)"`
+	Init           *InitCommand           `arg:"subcommand:init" help:"Create configuration for a Windchill installation"`
+	ConfigCommand  *ConfigCommand         `arg:"subcommand:config" help:"Inspect and validate the effective configuration"`
+	Modules        *ModulesCommand        `arg:"subcommand:modules" help:"List registry modules with their aliases, order and sources"`
+	Alias          *AliasCommand          `arg:"subcommand:alias" help:"Suggest, add and remove module aliases"`
+	CustomCommands *CustomCommandsCommand `arg:"subcommand:custom" help:"List custom commands with their parameters"`
+	Completion     *CompletionCommand     `arg:"subcommand:completion" help:"Print shell completion script for bash, zsh or fish"`
+	Complete       *CompleteCommand       `arg:"subcommand:complete" help:"List completion candidates, used by completion scripts"`
+	parser         *arg.Parser
+}
+
+func (a *ProgramArguments) Description() string {
+	return "Windchill module builder."
+}
+
+func (a *ProgramArguments) WriteHelp(subcommand ...string) error {
+	return a.parser.WriteHelpForSubcommand(os.Stdout, subcommand...)
+}
+
+type RunCommand struct {
+	Build           []string `arg:"-b,--build" help:"Execute build [module_sources] / [module_*] / [@all] / [@range:First..Last] / [@group], each set optionally suffixed with _sources" complete:"module"`
+	TestUnit        []string `arg:"-u,--test-unit" help:"Execute [unit tests] / [unit test by name]" complete:"module"`
+	TestIntegration []string `arg:"-i,--test-integration" help:"Execute [integ tests] / [integ test by name]" complete:"module"`
+	TestSelenium    []string `arg:"-s,--test-selenium" help:"Execute [selenium tests] / [selenium test by name]" complete:"module"`
+	Custom          []string `arg:"-c,--custom" help:"Execute custom command defined in CFG, optionally for a module and with arguments [name@module:value,param=value]" complete:"custom"`
+	NumKey          []string `arg:"-n,--num-key" help:"Execute numkey build" complete:"module"`
+	Stop            bool     `arg:"--stop" help:"Execute stop, waiting for stop_check when configured"`
+	ClearCache      bool     `arg:"--clear-cache" help:"Execute clear_cache before builds"`
+	RebuildClient   bool     `arg:"--rebuild-client" help:"Execute rebuild_client after builds"`
+	Start           bool     `arg:"--start" help:"Execute start, waiting for start_check when configured"`
+	Restart         bool     `arg:"-r,--restart" help:"Execute restart, waiting for restart_check when configured. Use --restart=auto to restart only when a completed build touched a source set with requires_restart"`
+	RestartAuto     bool     `arg:"--restart-auto" help:"Same as --restart=auto"`
+	Status          bool     `arg:"--status" help:"Execute status"`
+	Dry             bool     `arg:"-d,--dry" help:"Just generate commands."`
+}
+
+func (c *RunCommand) Lifecycle(target Target) bool {
+	switch target {
+	case Stop:
+		return c.Stop
+	case ClearCache:
+		return c.ClearCache
+	case RebuildClient:
+		return c.RebuildClient
+	case Start:
+		return c.Start
+	case Restart:
+		return c.Restart || c.RestartAuto
+	case Status:
+		return c.Status
+	}
+	return false
+}
+
+type InitCommand struct {
+	Root   string `arg:"--root" help:"Windchill installation root, defaults to WT_HOME or the current directory" complete:"dir"`
+	Output string `arg:"--output" help:"Configuration file to write, defaults to the user configuration file" complete:"file"`
+	Yes    bool   `arg:"-y,--yes" help:"Accept detected values without asking"`
+	Force  bool   `arg:"-f,--force" help:"Overwrite an existing configuration file"`
+}
+
+type ModulesCommand struct {
+	Pattern string `arg:"positional" help:"Regular expression matched against module names"`
+	Missing bool   `arg:"--missing" help:"Only modules whose directory does not exist"`
+	Has     string `arg:"--has" help:"Only modules with the given source directory, e.g. src_web"`
+	JSON    bool   `arg:"--json" help:"Print modules as JSON"`
+}
+
+type AliasCommand struct {
+	Suggest *AliasSuggestCommand `arg:"subcommand:suggest" help:"Propose unique aliases for registry modules without one"`
+	Add     *AliasAddCommand     `arg:"subcommand:add" help:"Add an alias to the configuration file"`
+	Remove  *AliasRemoveCommand  `arg:"subcommand:remove" help:"Remove an alias from the configuration file"`
+}
+
+type AliasSuggestCommand struct {
+	Apply bool   `arg:"--apply" help:"Write suggested aliases to the configuration file"`
+	File  string `arg:"--file" help:"Configuration file to edit, defaults to the most specific loaded one" complete:"file"`
+}
+
+type AliasAddCommand struct {
+	Alias  string `arg:"positional,required" help:"Alias to add"`
+	Module string `arg:"positional,required" help:"Registry module name"`
+	Force  bool   `arg:"-f,--force" help:"Replace an existing alias"`
+	File   string `arg:"--file" help:"Configuration file to edit, defaults to the most specific loaded one" complete:"file"`
+}
+
+type AliasRemoveCommand struct {
+	Alias string `arg:"positional,required" help:"Alias to remove"`
+	File  string `arg:"--file" help:"Configuration file to edit, defaults to the file defining the alias" complete:"file"`
+}
+
+type CustomCommandsCommand struct {
+	List *CustomListCommand `arg:"subcommand:list" help:"List custom commands, their parameters and help"`
+}
+
+type CustomListCommand struct{}
+
+type CompletionCommand struct {
+	Shell string `arg:"positional,required" help:"One of bash, zsh, fish"`
+}
+
+type CompleteCommand struct {
+	Kind   string `arg:"positional,required" help:"One of module, custom, profile"`
+	Prefix string `arg:"positional" help:"Word being completed"`
+}
+
+type ConfigCommand struct {
+	Show     *ConfigShowCommand     `arg:"subcommand:show" help:"Print the effective configuration"`
+	Validate *ConfigValidateCommand `arg:"subcommand:validate" help:"Validate configuration, inputs and aliases"`
+}
+
+type ConfigShowCommand struct {
+	Origin bool `arg:"--origin" help:"Annotate every value with the file it came from"`
+}
+
+type ConfigValidateCommand struct{}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func Test_withDefaultSubcommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "Should default flags to run",
+			args: []string{"-b", "mpml_s", "-r"},
+			want: []string{"run", "-b", "mpml_s", "-r"},
+		},
+		{
+			name: "Should default empty arguments to run",
+			args: []string{},
+			want: []string{"run"},
+		},
+		{
+			name: "Should keep explicit subcommand after global flags",
+			args: []string{"--profile", "ci", "--config=a.yml", "config", "show"},
+			want: []string{"--profile", "ci", "--config=a.yml", "config", "show"},
+		},
+		{
+			name: "Should keep help request",
+			args: []string{"--help"},
+			want: []string{"--help"},
+		},
+		{
+			name: "Should not treat flag values as subcommands",
+			args: []string{"-c", "init"},
+			want: []string{"run", "-c", "init"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withDefaultSubcommand(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withDefaultSubcommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Completions(t *testing.T) {
+	completions := Completions()
+	if completions[0].Name != "" || !reflect.DeepEqual(completions[0].Subcommands, subcommandNames()) {
+		t.Fatalf("Completions() root = %v", completions[0])
+	}
+	tests := []struct {
+		name    string
+		command string
+		flag    string
+		want    CompletionFlag
+	}{
+		{
+			name:    "Should complete build flag with modules",
+			command: "run",
+			flag:    "--build",
+			want:    CompletionFlag{Names: []string{"-b", "--build"}, Kind: CompleteModule, Multiple: true},
+		},
+		{
+			name:    "Should mark restart as switch",
+			command: "run",
+			flag:    "--restart",
+			want:    CompletionFlag{Names: []string{"-r", "--restart"}, Switch: true},
+		},
+		{
+			name:    "Should include nested subcommand flags",
+			command: "alias",
+			flag:    "--file",
+			want:    CompletionFlag{Names: []string{"--file"}, Kind: CompleteFile},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, completion := range completions {
+				if completion.Name != tt.command {
+					continue
+				}
+				for _, flag := range completion.Flags {
+					flag.Help = ""
+					if slices.Contains(flag.Names, tt.flag) && reflect.DeepEqual(flag, tt.want) {
+						return
+					}
+				}
+			}
+			t.Errorf("Completions() has no %v for %s", tt.want, tt.command)
+		})
+	}
+}
+
+func Test_withRestartMode(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "Should keep plain restart",
+			args: []string{"-b", "mpml_s", "-r"},
+			want: []string{"-b", "mpml_s", "-r"},
+		},
+		{
+			name: "Should map restart=auto",
+			args: []string{"--restart=auto", "-b", "mpml_s"},
+			want: []string{"--restart-auto", "-b", "mpml_s"},
+		},
+		{
+			name: "Should map separate auto value",
+			args: []string{"-b", "mpml_s", "-r", "auto"},
+			want: []string{"-b", "mpml_s", "--restart-auto"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withRestartMode(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withRestartMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
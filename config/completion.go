@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	CompleteModule  = "module"
+	CompleteCustom  = "custom"
+	CompleteProfile = "profile"
+	CompleteFile    = "file"
+	CompleteDir     = "dir"
+)
+
+type CompletionFlag struct {
+	Names    []string
+	Help     string
+	Kind     string
+	Switch   bool
+	Multiple bool
+}
+
+type CommandCompletion struct {
+	Name        string
+	Subcommands []string
+	Flags       []CompletionFlag
+}
+
+func Completions() []CommandCompletion {
+	t := reflect.TypeOf(ProgramArguments{})
+	result := []CommandCompletion{commandCompletion("", t)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name, ok := strings.CutPrefix(field.Tag.Get("arg"), "subcommand:"); ok {
+			result = append(result, withNestedFlags(commandCompletion(name, field.Type.Elem()), field.Type.Elem()))
+		}
+	}
+	return result
+}
+
+func commandCompletion(name string, t reflect.Type) CommandCompletion {
+	completion := CommandCompletion{Name: name}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("arg")
+		if subcommand, ok := strings.CutPrefix(tag, "subcommand:"); ok {
+			completion.Subcommands = append(completion.Subcommands, subcommand)
+			continue
+		}
+		if !strings.HasPrefix(tag, "-") {
+			continue
+		}
+		completion.Flags = append(completion.Flags, CompletionFlag{
+			Names:    strings.Split(tag, ","),
+			Help:     field.Tag.Get("help"),
+			Kind:     field.Tag.Get("complete"),
+			Switch:   field.Type.Kind() == reflect.Bool,
+			Multiple: field.Type.Kind() == reflect.Slice,
+		})
+	}
+	return completion
+}
+
+func withNestedFlags(completion CommandCompletion, t reflect.Type) CommandCompletion {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := strings.CutPrefix(field.Tag.Get("arg"), "subcommand:")
+		if !ok {
+			continue
+		}
+		for _, flag := range commandCompletion(name, field.Type.Elem()).Flags {
+			if !slices.ContainsFunc(completion.Flags, func(existing CompletionFlag) bool { return slices.Equal(existing.Names, flag.Names) }) {
+				completion.Flags = append(completion.Flags, flag)
+			}
+		}
+	}
+	return completion
+}
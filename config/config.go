@@ -1,56 +1,58 @@
 package config
 
 import (
-	"errors"
-	"fmt"
-	"github.com/alexflint/go-arg"
+	"slices"
+
 	"gopkg.in/yaml.v3"
-	"io/fs"
-	"os"
 )
 
-const CfgFileContent = `profile: prod
-root: /opt
-fail_on_error: false
-commands:
-  ootb:
-    restart: echo "Restarting"
-  custom:
-    full: echo "Full"
-input:
-  build_order: ignored/compile.includes
-  module_registry: ignored/moduleRegistry.xml
-aliases:
-  mpml: MPMLink
-  mpmlc: MPMLinkCommon
-  ppb: ProcessPlanBrowser
-  ass: Associative
-`
-
-func ParseCmdArgs() *ProgramArguments {
-	args := &ProgramArguments{}
-	arg.MustParse(args)
-	return args
+type OOTBCommands struct {
+	Start              string         `yaml:",omitempty"`
+	StartCheck         ReadinessCheck `yaml:"start_check,omitempty"`
+	Stop               string         `yaml:",omitempty"`
+	StopCheck          ReadinessCheck `yaml:"stop_check,omitempty"`
+	Restart            string         `yaml:",omitempty"`
+	RestartCheck       ReadinessCheck `yaml:"restart_check,omitempty"`
+	Status             string         `yaml:",omitempty"`
+	StatusCheck        ReadinessCheck `yaml:"status_check,omitempty"`
+	ClearCache         string         `yaml:"clear_cache,omitempty"`
+	ClearCacheCheck    ReadinessCheck `yaml:"clear_cache_check,omitempty"`
+	RebuildClient      string         `yaml:"rebuild_client,omitempty"`
+	RebuildClientCheck ReadinessCheck `yaml:"rebuild_client_check,omitempty"`
 }
 
-type ProgramArguments struct {
-	Build           []string `arg:"-b,--build" help:"Execute build "`
-	TestUnit        []string `arg:"-u,--test-unit" help:"Execute [unit tests] / [unit test by name]"`
-	TestIntegration []string `arg:"-i,--test-integration" help:"Execute [integ tests] / [integ test by name]"`
-	TestSelenium    []string `arg:"-s,--test-selenium" help:"Execute [selenium tests] / [selenium test by name]"`
-	Custom          []string `arg:"-c,--custom" help:"Execute custom command defined in CFG"`
-	NumKey          []string `arg:"-n,--num-key" help:"Execute numkey build"`
-	Restart         bool     `arg:"-r,--restart" help:"Execute restart"`
-	Dry             bool     `arg:"-d,--dry" help:"Just generate commands."`
+func (c *OOTBCommands) Lifecycle(target Target) (string, *ReadinessCheck) {
+	switch target {
+	case Start:
+		return c.Start, &c.StartCheck
+	case Stop:
+		return c.Stop, &c.StopCheck
+	case Restart:
+		return c.Restart, &c.RestartCheck
+	case Status:
+		return c.Status, &c.StatusCheck
+	case ClearCache:
+		return c.ClearCache, &c.ClearCacheCheck
+	case RebuildClient:
+		return c.RebuildClient, &c.RebuildClientCheck
+	}
+	return "", nil
 }
 
-type OOTBCommands struct {
-	Restart string
+func (c *OOTBCommands) validateChecks() error {
+	for _, target := range LifecycleTargets() {
+		_, check := c.Lifecycle(target)
+		err := check.validate(target.String() + "_check")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type Commands struct {
 	OOTB   OOTBCommands
-	Custom map[string]string
+	Custom map[string]CustomCommand
 }
 
 type Input struct {
@@ -59,56 +61,43 @@ type Input struct {
 }
 
 type AppConfig struct {
-	Profile     string
-	Root        string
-	FailOnError bool `yaml:"fail_on_error"`
-	Commands    Commands
-	Input       Input
-	Aliases     map[string]string
+	Include        StringList
+	Profile        string
+	Root           string
+	FailOnError    bool   `yaml:"fail_on_error"`
+	SpecValidation string `yaml:"spec_validation"`
+	LogDir         string `yaml:"log_dir"`
+	Env            map[string]string
+	TargetEnv      map[string]map[string]string `yaml:"target_env"`
+	JavaHome       string                       `yaml:"java_home"`
+	AntOpts        string                       `yaml:"ant_opts"`
+	Commands       Commands
+	Hooks          Hooks
+	Templates      Templates
+	SourceSets     map[string]SourceSet `yaml:"source_sets"`
+	Input          Input
+	Aliases        map[string]string
+	Groups         map[string][]string
+	Modules        map[string]ModuleOverride
+	Profiles       map[string]AppConfig
+	Origins        map[string]Origin `yaml:"-"`
+	Files          []string          `yaml:"-"`
+	effective      *yaml.Node
 }
 
-func CreateAppConfig() (*AppConfig, error) {
-	dir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("user HomeDirectory is not available. %w", err)
-	}
-	var appConfigDir = fmt.Sprintf("%s/.wc_builder", dir)
-	var configPath = fmt.Sprintf("%s/cfg.yml", appConfigDir)
-
-	_, err = os.Stat(configPath)
-	if errors.Is(err, fs.ErrNotExist) {
-		err := createFileWhenConfigMissing(appConfigDir, configPath)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	bytes, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("could not read configuration content. %w", err)
-	}
-
-	c := &AppConfig{}
-	err = yaml.Unmarshal(bytes, c)
-	if err != nil {
-		return nil, fmt.Errorf("in file %q: %w", configPath, err)
-	}
+func (c *AppConfig) CommandTemplates() Templates {
+	return DefaultTemplates().Merge(c.Templates)
+}
 
-	return c, err
+func (c *AppConfig) WarnOnInvalidSpec() bool {
+	return c.SpecValidation == SpecValidationWarn
 }
 
-func createFileWhenConfigMissing(appConfigDir string, configPath string) error {
-	err := os.Mkdir(appConfigDir, os.ModePerm)
-	if errors.Is(err, fs.ErrNotExist) {
-		return fmt.Errorf("could not create configuration directory in user home dir. %w", err)
-	}
-	configFile, err := os.Create(configPath)
-	if err != nil {
-		return fmt.Errorf("could not create configuration file in config dir. %w", err)
+func SortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
 	}
-	_, err = configFile.WriteString(CfgFileContent)
-	if err != nil {
-		return fmt.Errorf("could not write configuration to config file. %w", err)
-	}
-	return nil
+	slices.Sort(keys)
+	return keys
 }
@@ -22,31 +22,14 @@ const ClobberSymbol = "c"
 const SeleniumSymbol = "f"
 const UpgradeSymbol = "u"
 const HybridSymbol = "h"
+const AllSourcesSymbol = "*"
 
-var SrcAliases = map[string]string{
-	SrcSymbol:      SRC,
-	SrcTestSymbol:  SrcTest,
-	SrcWebSymbol:   SrcWeb,
-	SeleniumSymbol: SrcSelenium,
-	UpgradeSymbol:  SrcUpgrade,
-	HybridSymbol:   SrcHybrid,
-}
-var ClobberableSources = map[string]string{
-	SrcSymbol:      SRC,
-	SrcTestSymbol:  SrcTest,
-	SeleniumSymbol: SrcSelenium,
-	UpgradeSymbol:  SrcUpgrade,
-	HybridSymbol:   SrcHybrid,
-}
-var NonClobberableSources = map[string]string{
-	SrcWebSymbol: SrcWeb,
-}
-var TestSources = []string{SRC, SrcTest, SrcWeb}
+const ModuleSetPrefix = "@"
+const AllModulesSet = "all"
+const RangeModulesSet = "range:"
+const RangeSeparator = ".."
+
+const SpecValidationError = "error"
+const SpecValidationWarn = "warn"
 
-const BuildCommandFormat = "ant -f %s/%s/build.xml"
-const ClobberCommandFormat = "ant clobber -f %s/%s/build.xml"
-const TestCommandFormat = "ant %s -f %s/%s/build.xml"
-const SpecificTestCommandFormat = " -Dtest.includes=**/%s"
-const NumKeyBuildCommandFormat = `ant -v -f /opt/wnc/tools_vs/build/commonUtils.xml darjeeling.start_dbserver
-ant -f %s/%s/build.xml clean clobber all -Ddarjeeling.updnumkey=true
-ant -v -f /opt/wnc/tools_vs/build/commonUtils.xml darjeeling.stop_dbserver`
+var TestSources = []string{SRC, SrcTest, SrcWeb}
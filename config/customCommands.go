@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CustomArgumentsSeparator = ":"
+	CustomArgumentSeparator  = ","
+	CustomNamedSeparator     = "="
+	CustomModuleSeparator    = "@"
+	CustomModuleKey          = "Module"
+	CustomRootKey            = "Root"
+)
+
+const (
+	ParamString = "string"
+	ParamInt    = "int"
+	ParamBool   = "bool"
+	ParamModule = "module"
+)
+
+var paramTypes = []string{ParamString, ParamInt, ParamBool, ParamModule}
+
+type CustomParam struct {
+	Name     string
+	Type     string `yaml:",omitempty"`
+	Default  string `yaml:",omitempty"`
+	Required bool   `yaml:",omitempty"`
+	Help     string `yaml:",omitempty"`
+}
+
+func (p *CustomParam) ParamType() string {
+	if p.Type == "" {
+		return ParamString
+	}
+	return p.Type
+}
+
+func (p *CustomParam) validateDefault() error {
+	if p.Default == "" {
+		return nil
+	}
+	var err error
+	switch p.ParamType() {
+	case ParamInt:
+		_, err = strconv.Atoi(p.Default)
+	case ParamBool:
+		_, err = strconv.ParseBool(p.Default)
+	}
+	return err
+}
+
+type CustomStep struct {
+	Name            string `yaml:",omitempty"`
+	Command         string
+	Timeout         time.Duration `yaml:",omitempty"`
+	Workdir         string        `yaml:",omitempty"`
+	ContinueOnError bool          `yaml:"continue_on_error,omitempty"`
+	Finally         bool          `yaml:",omitempty"`
+	NoShell         bool          `yaml:"no_shell,omitempty"`
+}
+
+func (s *CustomStep) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = CustomStep{Command: value.Value}
+		return nil
+	}
+	type plain CustomStep
+	return value.Decode((*plain)(s))
+}
+
+type CustomCommand struct {
+	Command  string        `yaml:",omitempty"`
+	Workdir  string        `yaml:",omitempty"`
+	Steps    []CustomStep  `yaml:",omitempty"`
+	Help     string        `yaml:",omitempty"`
+	Params   []CustomParam `yaml:",omitempty"`
+	Finally  bool          `yaml:",omitempty"`
+	NoShell  bool          `yaml:"no_shell,omitempty"`
+	Template bool          `yaml:",omitempty"`
+}
+
+func (c *CustomCommand) Defined() bool {
+	return c.Command != "" || len(c.Steps) > 0
+}
+
+func (c *CustomCommand) CommandSteps() []CustomStep {
+	if len(c.Steps) > 0 {
+		return c.Steps
+	}
+	return []CustomStep{{Command: c.Command, Workdir: c.Workdir, Finally: c.Finally, NoShell: c.NoShell}}
+}
+
+func (c *CustomCommand) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = CustomCommand{Command: value.Value}
+		return nil
+	}
+	type plain CustomCommand
+	return value.Decode((*plain)(c))
+}
+
+func (c *CustomCommand) Param(name string) (CustomParam, bool) {
+	idx := slices.IndexFunc(c.Params, func(param CustomParam) bool { return param.Name == name })
+	if idx < 0 {
+		return CustomParam{}, false
+	}
+	return c.Params[idx], true
+}
+
+func (c *CustomCommand) validate(name string) error {
+	if c.Command != "" && len(c.Steps) > 0 {
+		return fmt.Errorf("custom command %s defines both command and steps", name)
+	}
+	for idx, step := range c.Steps {
+		if step.Command == "" {
+			return fmt.Errorf("custom command %s step %d has no command", name, idx+1)
+		}
+	}
+	seen := make([]string, 0, len(c.Params))
+	for _, param := range c.Params {
+		switch {
+		case param.Name == "":
+			return fmt.Errorf("custom command %s has a parameter without name", name)
+		case slices.Contains(seen, param.Name):
+			return fmt.Errorf("custom command %s declares parameter %s twice", name, param.Name)
+		case !slices.Contains(paramTypes, param.ParamType()):
+			return fmt.Errorf("custom command %s parameter %s has type %q, use one of %s", name, param.Name, param.Type, strings.Join(paramTypes, ", "))
+		case param.Name == CustomModuleKey || param.Name == CustomRootKey:
+			return fmt.Errorf("custom command %s parameter name %s is reserved", name, param.Name)
+		case param.Required && param.Default != "":
+			return fmt.Errorf("custom command %s parameter %s is required and has a default", name, param.Name)
+		}
+		if err := param.validateDefault(); err != nil {
+			return fmt.Errorf("custom command %s parameter %s default %q is not %s. %w", name, param.Name, param.Default, param.ParamType(), err)
+		}
+		seen = append(seen, param.Name)
+	}
+	return nil
+}
+
+func (c *Commands) validateCustom() error {
+	for name, command := range c.Custom {
+		if strings.ContainsAny(name, CustomArgumentsSeparator+CustomArgumentSeparator+CustomModuleSeparator) {
+			return fmt.Errorf("custom command name %q must not contain %q, %q or %q", name, CustomArgumentsSeparator, CustomArgumentSeparator, CustomModuleSeparator)
+		}
+		err := command.validate(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func Test_CustomCommand_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  []CustomParam
+		wantErr bool
+	}{
+		{name: "Should accept typed defaults", params: []CustomParam{{Name: "count", Type: ParamInt, Default: "2"}, {Name: "verbose", Type: ParamBool, Default: "true"}}},
+		{name: "Should reject int default", params: []CustomParam{{Name: "count", Type: ParamInt, Default: "abc"}}, wantErr: true},
+		{name: "Should reject bool default", params: []CustomParam{{Name: "verbose", Type: ParamBool, Default: "maybe"}}, wantErr: true},
+		{name: "Should reject reserved name", params: []CustomParam{{Name: CustomRootKey}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := &CustomCommand{Command: "deploy", Params: tt.params}
+			if err := command.validate("deploy"); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+func (c *AppConfig) EditableFile(path string) string {
+	if origin, ok := c.Origins[path]; ok && origin.File != BuiltinOrigin && fileExists(origin.File) {
+		return origin.File
+	}
+	for i := len(c.Files) - 1; i >= 0; i-- {
+		if c.Files[i] != BuiltinOrigin {
+			return c.Files[i]
+		}
+	}
+	return ""
+}
+
+func SetMappingValues(file string, section string, values map[string]string) error {
+	return editSection(file, section, func(mapping *yaml.Node) {
+		for _, key := range SortedKeys(values) {
+			if existing := mappingValue(mapping, key); existing != nil {
+				existing.Kind, existing.Tag, existing.Value, existing.Style = yaml.ScalarNode, "!!str", values[key], 0
+				continue
+			}
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: values[key]})
+		}
+	})
+}
+
+func RemoveMappingKey(file string, section string, key string) error {
+	found := false
+	err := editSection(file, section, func(mapping *yaml.Node) {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == key {
+				mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+				found = true
+				return
+			}
+		}
+	})
+	if err == nil && !found {
+		return fmt.Errorf("key %q not found in %s of %q", key, section, file)
+	}
+	return err
+}
+
+func editSection(file string, section string, edit func(mapping *yaml.Node)) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("could not read configuration content. %w", err)
+	}
+	document := &yaml.Node{}
+	err = yaml.Unmarshal(content, document)
+	if err != nil {
+		return fmt.Errorf("in file %q: %w", file, err)
+	}
+	if len(document.Content) == 0 {
+		document.Kind = yaml.DocumentNode
+		document.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("in file %q: configuration root must be a mapping", file)
+	}
+	mapping := mappingValue(root, section)
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		if mapping == nil {
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: section})
+			mapping = &yaml.Node{}
+			root.Content = append(root.Content, mapping)
+		}
+		*mapping = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	edit(mapping)
+
+	output := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&output)
+	encoder.SetIndent(2)
+	err = encoder.Encode(document)
+	if err != nil {
+		return fmt.Errorf("could not encode configuration. %w", err)
+	}
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("could not encode configuration. %w", err)
+	}
+	err = os.WriteFile(file, output.Bytes(), 0o644)
+	if err != nil {
+		return fmt.Errorf("could not write configuration to %q. %w", file, err)
+	}
+	return nil
+}
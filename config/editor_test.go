@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_editSection(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cfg.yml")
+	writeConfigFile(t, file, "# team settings\nroot: /opt # install\naliases:\n  a: ModuleA\n  b: ModuleB\n")
+
+	err := SetMappingValues(file, "aliases", map[string]string{"c": "ModuleC", "a": "ModuleAA"})
+	if err != nil {
+		t.Fatalf("SetMappingValues() error = %v", err)
+	}
+	err = RemoveMappingKey(file, "aliases", "b")
+	if err != nil {
+		t.Fatalf("RemoveMappingKey() error = %v", err)
+	}
+	err = RemoveMappingKey(file, "aliases", "missing")
+	if err == nil {
+		t.Errorf("RemoveMappingKey() expected error for missing key")
+	}
+
+	got, _ := os.ReadFile(file)
+	want := "# team settings\nroot: /opt # install\naliases:\n  a: ModuleAA\n  c: ModuleC\n"
+	if string(got) != want {
+		t.Errorf("edited file = %q, want %q", got, want)
+	}
+}
@@ -7,13 +7,14 @@ const (
 	Running
 	Completed
 	Failed
+	Skipped
 )
 
 func (t ExecutionStatus) String() string {
-	return [...]string{"PREPARED", "RUNNING", "COMPLETED", "FAILED"}[t]
+	return [...]string{"PREPARED", "RUNNING", "COMPLETED", "FAILED", "SKIPPED"}[t]
 }
 func (t ExecutionStatus) Color() string {
-	return [...]string{WarningColor, WarningColor, OkColor, ErrColor}[t]
+	return [...]string{WarningColor, WarningColor, OkColor, ErrColor, WarningColor}[t]
 }
 func (t ExecutionStatus) EnumIndex() int {
 	return int(t)
@@ -29,10 +30,17 @@ const (
 	Restart
 	Custom
 	NumKey
+	Start
+	Stop
+	Status
+	ClearCache
+	RebuildClient
 )
 
+var targetNames = [...]string{"build", "test_unit", "test_integration", "test_selenium", "restart", "custom", "num_key", "start", "stop", "status", "clear_cache", "rebuild_client"}
+
 func (t Target) String() string {
-	return [...]string{"build", "test_unit", "test_integration", "test_selenium", "restart", "custom"}[t]
+	return targetNames[t]
 }
 func (t Target) ModuleDependent() []string {
 	return []string{"build", "test_unit", "test_integration", "test_selenium"}
@@ -40,6 +48,9 @@ func (t Target) ModuleDependent() []string {
 func (t Target) ModuleAgnostic() []string {
 	return []string{"build", "test_unit", "test_integration", "test_selenium"}
 }
+func LifecycleTargets() []Target {
+	return []Target{Stop, ClearCache, RebuildClient, Start, Restart, Status}
+}
 func (t Target) EnumIndex() int {
 	return int(t)
 }
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const JavaHomeEnvVariable = "JAVA_HOME"
+const AntOptsEnvVariable = "ANT_OPTS"
+const PathEnvVariable = "PATH"
+
+func (c *AppConfig) Environment(targets ...Target) map[string]string {
+	env := make(map[string]string, len(c.Env))
+	for key, value := range c.Env {
+		env[key] = value
+	}
+	if c.JavaHome != "" {
+		path, ok := env[PathEnvVariable]
+		if !ok {
+			path = os.Getenv(PathEnvVariable)
+		}
+		env[JavaHomeEnvVariable] = c.JavaHome
+		env[PathEnvVariable] = filepath.Join(c.JavaHome, "bin") + string(os.PathListSeparator) + path
+	}
+	if c.AntOpts != "" {
+		env[AntOptsEnvVariable] = c.AntOpts
+	}
+	for _, target := range targets {
+		for key, value := range c.TargetEnv[target.String()] {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+func (c *AppConfig) validateTargetEnv() error {
+	for name := range c.TargetEnv {
+		if _, ok := ParseTarget(name); !ok {
+			return fmt.Errorf("target_env has unknown target %q, use one of %v", name, targetNames)
+		}
+	}
+	return nil
+}
+
+func ParseTarget(name string) (Target, bool) {
+	idx := slices.Index(targetNames[:], name)
+	return Target(idx), idx >= 0
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_AppConfig_Environment(t *testing.T) {
+	cfg := &AppConfig{
+		Env:       map[string]string{"WT_HOME": "/opt/wt", "PATH": "/usr/bin", "LANG": "C"},
+		JavaHome:  "/opt/jdk",
+		AntOpts:   "-Xmx2g",
+		TargetEnv: map[string]map[string]string{"test_selenium": {"DISPLAY": ":99", "LANG": "en_US"}},
+	}
+	tests := []struct {
+		name    string
+		targets []Target
+		want    map[string]string
+	}{
+		{
+			name: "Should combine global env with java home and ant opts",
+			want: map[string]string{
+				"WT_HOME":   "/opt/wt",
+				"LANG":      "C",
+				"JAVA_HOME": "/opt/jdk",
+				"PATH":      filepath.Join("/opt/jdk", "bin") + string(os.PathListSeparator) + "/usr/bin",
+				"ANT_OPTS":  "-Xmx2g",
+			},
+		},
+		{
+			name:    "Should override with target env",
+			targets: []Target{TestSelenium},
+			want: map[string]string{
+				"WT_HOME":   "/opt/wt",
+				"LANG":      "en_US",
+				"DISPLAY":   ":99",
+				"JAVA_HOME": "/opt/jdk",
+				"PATH":      filepath.Join("/opt/jdk", "bin") + string(os.PathListSeparator) + "/usr/bin",
+				"ANT_OPTS":  "-Xmx2g",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.Environment(tt.targets...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Environment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_AppConfig_validateTargetEnv(t *testing.T) {
+	cfg := &AppConfig{TargetEnv: map[string]map[string]string{"test_unit": {}, "tests": {}}}
+	if err := cfg.validateTargetEnv(); err == nil {
+		t.Errorf("validateTargetEnv() expected error for unknown target")
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var envPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?}`)
+
+type StringList []string
+
+func (l *StringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = StringList{value.Value}
+		return nil
+	}
+	var values []string
+	err := value.Decode(&values)
+	if err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+func expandValue(value string) string {
+	return envPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		groups := envPattern.FindStringSubmatch(match)
+		if resolved, ok := os.LookupEnv(groups[1]); ok && (resolved != "" || groups[2] == "") {
+			return resolved
+		}
+		return groups[3]
+	})
+}
+
+func expandEnv(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		if !strings.Contains(node.Value, "$") {
+			return
+		}
+		node.Value = expandValue(node.Value)
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+		return
+	}
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+		expandEnv(child)
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Hook struct {
+	Command string
+	Target  StringList `yaml:",omitempty"`
+	Module  StringList `yaml:",omitempty"`
+}
+
+func (h *Hook) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*h = Hook{Command: value.Value}
+		return nil
+	}
+	type plain Hook
+	return value.Decode((*plain)(h))
+}
+
+func (h *Hook) Matches(target Target, modules ...string) bool {
+	if len(h.Target) > 0 && !slices.Contains(h.Target, target.String()) {
+		return false
+	}
+	if len(h.Module) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(modules, func(module string) bool { return slices.Contains(h.Module, module) })
+}
+
+type HookList []Hook
+
+func (l *HookList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = HookList{{Command: value.Value}}
+		return nil
+	}
+	var hooks []Hook
+	err := value.Decode(&hooks)
+	if err != nil {
+		return err
+	}
+	*l = hooks
+	return nil
+}
+
+type Hooks struct {
+	BeforeRun  HookList `yaml:"before_run"`
+	AfterRun   HookList `yaml:"after_run"`
+	BeforeTask HookList `yaml:"before_task"`
+	AfterTask  HookList `yaml:"after_task"`
+	OnFailure  HookList `yaml:"on_failure"`
+}
+
+func (h *Hooks) validate() error {
+	lists := map[string]HookList{
+		"before_run":  h.BeforeRun,
+		"after_run":   h.AfterRun,
+		"before_task": h.BeforeTask,
+		"after_task":  h.AfterTask,
+		"on_failure":  h.OnFailure,
+	}
+	for _, name := range SortedKeys(lists) {
+		for _, hook := range lists[name] {
+			for _, target := range hook.Target {
+				if _, ok := ParseTarget(target); !ok {
+					return fmt.Errorf("hooks.%s has unknown target %q, use one of %v", name, target, targetNames)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func Test_Hooks_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   Hooks
+		wantErr bool
+	}{
+		{name: "Should accept known targets", hooks: Hooks{BeforeTask: HookList{{Command: "echo", Target: StringList{"build", "num_key"}}}}},
+		{name: "Should reject unknown target", hooks: Hooks{OnFailure: HookList{{Command: "echo", Target: StringList{"biuld"}}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hooks.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const ConfigEnvVariable = "WCB_CONFIG"
+const ProfileEnvVariable = "WCB_PROFILE"
+const ProjectConfigFile = ".wcb.yml"
+const UserConfigDir = ".wc_builder"
+const UserConfigFile = "cfg.yml"
+const BuiltinOrigin = "<built-in>"
+
+type Origin struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (o Origin) String() string {
+	if o.Line == 0 {
+		return o.File
+	}
+	return fmt.Sprintf("%s:%d:%d", o.File, o.Line, o.Column)
+}
+
+type LoadOptions struct {
+	ConfigPath string
+	Profile    string
+}
+
+type configLayer struct {
+	file string
+	node *yaml.Node
+}
+
+type configLoader struct {
+	merged           *yaml.Node
+	origins          map[string]Origin
+	files            []string
+	validationErrors ValidationErrors
+}
+
+func CreateAppConfig(options LoadOptions) (*AppConfig, error) {
+	paths, err := lookupConfigFiles(options)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := &configLoader{
+		merged:  &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"},
+		origins: make(map[string]Origin),
+	}
+	builtin, err := builtinLayer()
+	if err != nil {
+		return nil, err
+	}
+	loader.apply(builtin)
+	for _, path := range paths {
+		err = loader.load(path, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(loader.validationErrors) > 0 {
+		return nil, loader.validationErrors
+	}
+	err = loader.applyProfile(options)
+	if err != nil {
+		return nil, err
+	}
+	expandEnv(loader.merged)
+
+	c := &AppConfig{}
+	err = loader.merged.Decode(c)
+	if err != nil {
+		return nil, fmt.Errorf("in files %s: %w", strings.Join(loader.files, ", "), err)
+	}
+	err = c.validateSourceSets()
+	if err == nil {
+		err = c.Commands.OOTB.validateChecks()
+	}
+	if err == nil {
+		err = c.Commands.validateCustom()
+	}
+	if err == nil {
+		err = c.validateTargetEnv()
+	}
+	if err == nil {
+		err = c.Hooks.validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("in files %s: %w", strings.Join(loader.files, ", "), err)
+	}
+	c.Origins = loader.origins
+	c.Files = loader.files
+	c.effective = loader.merged
+	return c, nil
+}
+
+func lookupConfigFiles(options LoadOptions) ([]string, error) {
+	paths := make([]string, 0, 4)
+
+	userPath, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if fileExists(userPath) {
+		paths = append(paths, userPath)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("working directory is not available. %w", err)
+	}
+	if projectPath, found := findUpwards(cwd, ProjectConfigFile); found {
+		paths = append(paths, projectPath)
+	}
+
+	for _, explicit := range []string{os.Getenv(ConfigEnvVariable), options.ConfigPath} {
+		if explicit == "" {
+			continue
+		}
+		if !fileExists(explicit) {
+			return nil, fmt.Errorf("configuration file %q does not exist", explicit)
+		}
+		paths = append(paths, explicit)
+	}
+
+	return slices.Compact(paths), nil
+}
+
+func UserConfigPath() (string, error) {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("user HomeDirectory is not available. %w", err)
+	}
+	return filepath.Join(dir, UserConfigDir, UserConfigFile), nil
+}
+
+func findUpwards(dir string, name string) (string, bool) {
+	for {
+		candidate := filepath.Join(dir, name)
+		if fileExists(candidate) {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func builtinLayer() (configLayer, error) {
+	defaults := map[string]any{
+		"profile":         "prod",
+		"fail_on_error":   false,
+		"spec_validation": SpecValidationError,
+		"templates":       DefaultTemplates(),
+		"source_sets":     DefaultSourceSets(),
+	}
+	node := &yaml.Node{}
+	err := node.Encode(defaults)
+	if err != nil {
+		return configLayer{}, fmt.Errorf("could not prepare default configuration. %w", err)
+	}
+	return configLayer{file: BuiltinOrigin, node: node}, nil
+}
+
+func readLayer(path string) (configLayer, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return configLayer{}, fmt.Errorf("could not read configuration content. %w", err)
+	}
+	document := &yaml.Node{}
+	err = yaml.Unmarshal(bytes, document)
+	if err != nil {
+		return configLayer{}, fmt.Errorf("in file %q: %w", path, err)
+	}
+	if len(document.Content) == 0 {
+		return configLayer{file: path, node: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}}, nil
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return configLayer{}, fmt.Errorf("in file %q: configuration root must be a mapping", path)
+	}
+	return configLayer{file: path, node: root}, nil
+}
+
+func (l *configLoader) load(path string, including []string) error {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("could not resolve configuration path %q. %w", path, err)
+	}
+	if slices.Contains(including, absolute) {
+		return fmt.Errorf("configuration include cycle: %s", strings.Join(append(including, absolute), " -> "))
+	}
+	layer, err := readLayer(path)
+	if err != nil {
+		return err
+	}
+	l.validationErrors = append(l.validationErrors, validateLayer(layer)...)
+
+	includes, err := takeIncludes(layer)
+	if err != nil {
+		return err
+	}
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		if !fileExists(include) {
+			return fmt.Errorf("in file %q: included file %q does not exist", path, include)
+		}
+		err = l.load(include, append(including, absolute))
+		if err != nil {
+			return err
+		}
+	}
+	l.apply(layer)
+	return nil
+}
+
+func takeIncludes(layer configLayer) ([]string, error) {
+	content := make([]*yaml.Node, 0, len(layer.node.Content))
+	var includes StringList
+	for i := 0; i+1 < len(layer.node.Content); i += 2 {
+		key, value := layer.node.Content[i], layer.node.Content[i+1]
+		if key.Value != "include" {
+			content = append(content, key, value)
+			continue
+		}
+		err := value.Decode(&includes)
+		if err != nil {
+			return nil, fmt.Errorf("in file %q: %w", layer.file, err)
+		}
+	}
+	layer.node.Content = content
+	for i, include := range includes {
+		includes[i] = expandValue(include)
+	}
+	return includes, nil
+}
+
+func (l *configLoader) apply(layer configLayer) {
+	l.files = append(l.files, layer.file)
+	l.recordOrigins(layer.node, "", layer.file)
+	mergeMappings(l.merged, layer.node)
+}
+
+func (l *configLoader) applyProfile(options LoadOptions) error {
+	profile, source := options.Profile, "--profile"
+	if profile == "" {
+		profile, source = os.Getenv(ProfileEnvVariable), ProfileEnvVariable
+	}
+	explicit := profile != ""
+	if !explicit {
+		if configured := mappingValue(l.merged, "profile"); configured != nil {
+			profile = configured.Value
+		}
+	}
+	var profileNode *yaml.Node
+	if profiles := mappingValue(l.merged, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+		profileNode = mappingValue(profiles, profile)
+	}
+	if profileNode == nil || profileNode.Kind != yaml.MappingNode {
+		if explicit && mappingValue(l.merged, "profiles") != nil {
+			return fmt.Errorf("profile %q is not defined in configuration", profile)
+		}
+		if explicit {
+			l.setScalar("profile", profile)
+			l.origins["profile"] = Origin{File: source}
+		}
+		return nil
+	}
+
+	profilePath := joinPath("profiles", profile)
+	overrides := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(profileNode.Content); i += 2 {
+		key, value := profileNode.Content[i], profileNode.Content[i+1]
+		if key.Value == "profiles" || key.Value == "profile" {
+			continue
+		}
+		overrides.Content = append(overrides.Content, key, value)
+		l.copyOrigins(value, key.Value, joinPath(profilePath, key.Value))
+	}
+	mergeMappings(l.merged, overrides)
+	if explicit {
+		l.setScalar("profile", profile)
+		l.origins["profile"] = Origin{File: source}
+	}
+	return nil
+}
+
+func (l *configLoader) copyOrigins(node *yaml.Node, target string, source string) {
+	if node.Kind != yaml.MappingNode {
+		l.forgetOrigins(target)
+		l.origins[target] = l.origins[source]
+		return
+	}
+	delete(l.origins, target)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		l.copyOrigins(node.Content[i+1], joinPath(target, key), joinPath(source, key))
+	}
+}
+
+func (l *configLoader) setScalar(key string, value string) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	}}
+	mergeMappings(l.merged, node)
+}
+
+func (l *configLoader) recordOrigins(node *yaml.Node, path string, file string) {
+	if node.Kind != yaml.MappingNode {
+		l.forgetOrigins(path)
+		l.origins[path] = Origin{File: file, Line: node.Line, Column: node.Column}
+		return
+	}
+	delete(l.origins, path)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		l.recordOrigins(node.Content[i+1], joinPath(path, node.Content[i].Value), file)
+	}
+}
+
+func (l *configLoader) forgetOrigins(path string) {
+	for key := range l.origins {
+		if strings.HasPrefix(key, path+".") {
+			delete(l.origins, key)
+		}
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func mergeMappings(base *yaml.Node, override *yaml.Node) {
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		existing := mappingValue(base, key.Value)
+		switch {
+		case existing != nil && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeMappings(existing, value)
+		case existing != nil:
+			*existing = *copyNode(value)
+		default:
+			base.Content = append(base.Content, copyNode(key), copyNode(value))
+		}
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func copyNode(node *yaml.Node) *yaml.Node {
+	result := *node
+	result.HeadComment, result.LineComment, result.FootComment = "", "", ""
+	result.Content = make([]*yaml.Node, 0, len(node.Content))
+	for _, child := range node.Content {
+		result.Content = append(result.Content, copyNode(child))
+	}
+	return &result
+}
+
+func (c *AppConfig) Configured() bool {
+	return slices.ContainsFunc(c.Files, func(file string) bool { return file != BuiltinOrigin })
+}
+
+func (c *AppConfig) WriteEffective(w io.Writer, withOrigin bool) error {
+	if c.effective == nil {
+		return errors.New("effective configuration is not available")
+	}
+	node := copyNode(c.effective)
+	if withOrigin {
+		c.annotateOrigins(node, "")
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(node)
+	if err != nil {
+		return fmt.Errorf("could not print configuration. %w", err)
+	}
+	return encoder.Close()
+}
+
+func (c *AppConfig) annotateOrigins(node *yaml.Node, path string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		childPath := joinPath(path, key.Value)
+		if value.Kind == yaml.MappingNode {
+			c.annotateOrigins(value, childPath)
+			continue
+		}
+		if origin, ok := c.Origins[childPath]; ok {
+			key.LineComment = origin.String()
+		}
+	}
+}
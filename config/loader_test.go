@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path string, content string) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_CreateAppConfig(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	workDir := filepath.Join(project, "a", "b")
+	userFile := filepath.Join(home, UserConfigDir, UserConfigFile)
+	projectFile := filepath.Join(project, ProjectConfigFile)
+	explicitFile := filepath.Join(t.TempDir(), "explicit.yml")
+
+	writeConfigFile(t, userFile, "root: /user\naliases:\n  a: ModuleA\n  b: ModuleB\n")
+	writeConfigFile(t, projectFile, "root: /project\naliases:\n  b: ModuleBB\n")
+	writeConfigFile(t, explicitFile, "fail_on_error: true\n")
+	err := os.MkdirAll(workDir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	err = os.Chdir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv(ConfigEnvVariable, "")
+
+	got, err := CreateAppConfig(LoadOptions{ConfigPath: explicitFile})
+	if err != nil {
+		t.Fatalf("CreateAppConfig() error = %v", err)
+	}
+	if got.Root != "/project" || !got.FailOnError || got.SpecValidation != SpecValidationError {
+		t.Errorf("CreateAppConfig() root = %v, failOnError = %v, specValidation = %v", got.Root, got.FailOnError, got.SpecValidation)
+	}
+	wantAliases := map[string]string{"a": "ModuleA", "b": "ModuleBB"}
+	if !reflect.DeepEqual(got.Aliases, wantAliases) {
+		t.Errorf("CreateAppConfig() aliases = %v, want %v", got.Aliases, wantAliases)
+	}
+	wantOrigins := map[string]Origin{
+		"root":          {File: projectFile, Line: 1, Column: 7},
+		"aliases.a":     {File: userFile, Line: 3, Column: 6},
+		"aliases.b":     {File: projectFile, Line: 3, Column: 6},
+		"fail_on_error": {File: explicitFile, Line: 1, Column: 16},
+	}
+	for path, want := range wantOrigins {
+		if got.Origins[path] != want {
+			t.Errorf("CreateAppConfig() origin of %s = %v, want %v", path, got.Origins[path], want)
+		}
+	}
+
+	_, err = CreateAppConfig(LoadOptions{ConfigPath: filepath.Join(home, "missing.yml")})
+	if err == nil {
+		t.Errorf("CreateAppConfig() expected error for missing explicit file")
+	}
+}
+
+func Test_CreateAppConfig_profiles(t *testing.T) {
+	home := t.TempDir()
+	userFile := filepath.Join(home, UserConfigDir, UserConfigFile)
+	writeConfigFile(t, userFile, `profile: dev
+root: /opt
+aliases:
+  a: ModuleA
+profiles:
+  dev:
+    root: /dev
+  ci:
+    root: /ci
+    input:
+      build_order: /ci/compile.includes
+    aliases:
+      c: ModuleC
+`)
+	t.Setenv("HOME", home)
+	t.Setenv(ConfigEnvVariable, "")
+	tests := []struct {
+		name        string
+		options     LoadOptions
+		env         string
+		wantProfile string
+		wantRoot    string
+		wantAliases map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "Should use profile from configuration",
+			wantProfile: "dev",
+			wantRoot:    "/dev",
+			wantAliases: map[string]string{"a": "ModuleA"},
+		},
+		{
+			name:        "Should use profile from environment",
+			env:         "ci",
+			wantProfile: "ci",
+			wantRoot:    "/ci",
+			wantAliases: map[string]string{"a": "ModuleA", "c": "ModuleC"},
+		},
+		{
+			name:        "Should prefer profile from flag",
+			options:     LoadOptions{Profile: "dev"},
+			env:         "ci",
+			wantProfile: "dev",
+			wantRoot:    "/dev",
+			wantAliases: map[string]string{"a": "ModuleA"},
+		},
+		{
+			name:    "Should fail on unknown explicit profile",
+			options: LoadOptions{Profile: "unknown"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ProfileEnvVariable, tt.env)
+			got, err := CreateAppConfig(tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateAppConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Profile != tt.wantProfile || got.Root != tt.wantRoot || !reflect.DeepEqual(got.Aliases, tt.wantAliases) {
+				t.Errorf("CreateAppConfig() = %v %v %v, want %v %v %v", got.Profile, got.Root, got.Aliases, tt.wantProfile, tt.wantRoot, tt.wantAliases)
+			}
+		})
+	}
+}
+
+func Test_CreateAppConfig_includesAndExpansion(t *testing.T) {
+	home := t.TempDir()
+	userFile := filepath.Join(home, UserConfigDir, UserConfigFile)
+	sharedFile := filepath.Join(home, UserConfigDir, "team", "aliases.yml")
+	nestedFile := filepath.Join(home, UserConfigDir, "team", "nested.yml")
+	writeConfigFile(t, userFile, `include: team/aliases.yml
+root: ${WCB_TEST_ROOT:-/opt}/wt
+fail_on_error: ${WCB_TEST_FAIL}
+aliases:
+  b: ModuleB
+commands:
+  custom:
+    pid: echo $$
+    home: echo $${HOME}
+`)
+	writeConfigFile(t, sharedFile, "include: [nested.yml]\naliases:\n  a: ModuleA\n  b: SharedB\n")
+	writeConfigFile(t, nestedFile, "aliases:\n  n: ${WCB_TEST_MODULE}\n")
+	t.Setenv("HOME", home)
+	t.Setenv(ConfigEnvVariable, "")
+	t.Setenv(ProfileEnvVariable, "")
+	t.Setenv("WCB_TEST_ROOT", "")
+	t.Setenv("WCB_TEST_FAIL", "true")
+	t.Setenv("WCB_TEST_MODULE", "ModuleN")
+
+	got, err := CreateAppConfig(LoadOptions{})
+	if err != nil {
+		t.Fatalf("CreateAppConfig() error = %v", err)
+	}
+	if got.Root != "/opt/wt" || !got.FailOnError || got.Commands.Custom["pid"].Command != "echo $$" || got.Commands.Custom["home"].Command != "echo ${HOME}" {
+		t.Errorf("CreateAppConfig() root = %v, failOnError = %v, custom = %v", got.Root, got.FailOnError, got.Commands.Custom)
+	}
+	wantAliases := map[string]string{"a": "ModuleA", "b": "ModuleB", "n": "ModuleN"}
+	if !reflect.DeepEqual(got.Aliases, wantAliases) {
+		t.Errorf("CreateAppConfig() aliases = %v, want %v", got.Aliases, wantAliases)
+	}
+	if got.Origins["aliases.a"].File != sharedFile || got.Origins["aliases.n"].File != nestedFile {
+		t.Errorf("CreateAppConfig() origins = %v", got.Origins)
+	}
+
+	writeConfigFile(t, nestedFile, "include: ../cfg.yml\n")
+	_, err = CreateAppConfig(LoadOptions{})
+	if err == nil {
+		t.Errorf("CreateAppConfig() expected include cycle error")
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+type ModuleOverride struct {
+	BuildFile       string `yaml:"build_file"`
+	Properties      map[string]string
+	Env             map[string]string
+	Timeout         time.Duration
+	Workdir         string
+	DisabledSources []string `yaml:"disabled_sources"`
+	NoClobber       bool     `yaml:"no_clobber"`
+	Templates       Templates
+}
+
+func (c *AppConfig) ModuleOverride(name string) (ModuleOverride, bool) {
+	if override, ok := c.Modules[name]; ok {
+		return override, true
+	}
+	for alias, moduleName := range c.Aliases {
+		if moduleName != name {
+			continue
+		}
+		if override, ok := c.Modules[alias]; ok {
+			return override, true
+		}
+	}
+	return ModuleOverride{}, false
+}
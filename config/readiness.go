@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const DefaultReadinessTimeout = 10 * time.Minute
+const DefaultReadinessInterval = 5 * time.Second
+
+type ReadinessCheck struct {
+	URL      string        `yaml:",omitempty"`
+	Address  string        `yaml:",omitempty"`
+	Log      string        `yaml:",omitempty"`
+	Pattern  string        `yaml:",omitempty"`
+	Timeout  time.Duration `yaml:",omitempty"`
+	Interval time.Duration `yaml:",omitempty"`
+}
+
+func (c *ReadinessCheck) Enabled() bool {
+	return c.URL != "" || c.Address != "" || c.Log != ""
+}
+
+func (c *ReadinessCheck) WaitTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultReadinessTimeout
+}
+
+func (c *ReadinessCheck) PollInterval() time.Duration {
+	if c.Interval > 0 {
+		return c.Interval
+	}
+	return DefaultReadinessInterval
+}
+
+func (c *ReadinessCheck) validate(name string) error {
+	if (c.Log == "") != (c.Pattern == "") {
+		return fmt.Errorf("readiness check %s needs both log and pattern", name)
+	}
+	if _, err := regexp.Compile(c.Pattern); err != nil {
+		return fmt.Errorf("readiness check %s has invalid pattern %q. %w", name, c.Pattern, err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ValidationError struct {
+	Origin  Origin
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Origin, e.Message)
+}
+
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, "configuration is invalid:")
+	for _, validationError := range e {
+		lines = append(lines, "  "+validationError.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+func validateLayer(layer configLayer) ValidationErrors {
+	return validateNode(layer.node, reflect.TypeOf(AppConfig{}), "", layer.file)
+}
+
+func validateNode(node *yaml.Node, t reflect.Type, path string, file string) ValidationErrors {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && (node.Tag == "!!null" || envPattern.MatchString(node.Value)) {
+		return nil
+	}
+	origin := Origin{File: file, Line: node.Line, Column: node.Column}
+	if reflect.PointerTo(t).Implements(unmarshalerType) && node.Kind != yaml.MappingNode {
+		return validateDecode(node, t, path, origin)
+	}
+	switch {
+	case t == durationType:
+		if node.Kind != yaml.ScalarNode {
+			return ValidationErrors{{origin, fmt.Sprintf("%s must be a duration like 90s or 5m", describePath(path))}}
+		}
+		if _, err := time.ParseDuration(node.Value); err != nil {
+			return ValidationErrors{{origin, fmt.Sprintf("%s must be a duration like 90s or 5m, got %q", describePath(path), node.Value)}}
+		}
+		return nil
+	case t.Kind() == reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return ValidationErrors{{origin, fmt.Sprintf("%s must be a mapping", describePath(path))}}
+		}
+		return validateStruct(node, t, path, file)
+	case t.Kind() == reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return ValidationErrors{{origin, fmt.Sprintf("%s must be a mapping", describePath(path))}}
+		}
+		errs := make(ValidationErrors, 0)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			errs = append(errs, validateNode(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value), file)...)
+		}
+		return errs
+	case t.Kind() == reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return ValidationErrors{{origin, fmt.Sprintf("%s must be a list", describePath(path))}}
+		}
+		errs := make(ValidationErrors, 0)
+		for i, item := range node.Content {
+			errs = append(errs, validateNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), file)...)
+		}
+		return errs
+	}
+	if node.Kind != yaml.ScalarNode {
+		return ValidationErrors{{origin, fmt.Sprintf("%s must be a single %s value", describePath(path), t.Kind())}}
+	}
+	return validateDecode(node, t, path, origin)
+}
+
+func validateDecode(node *yaml.Node, t reflect.Type, path string, origin Origin) ValidationErrors {
+	err := node.Decode(reflect.New(t).Interface())
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	if typeError, ok := err.(*yaml.TypeError); ok && len(typeError.Errors) > 0 {
+		message = typeError.Errors[0]
+		if idx := strings.Index(message, ": "); strings.HasPrefix(message, "line ") && idx >= 0 {
+			message = message[idx+2:]
+		}
+	}
+	return ValidationErrors{{origin, fmt.Sprintf("%s has invalid value %q: %s", describePath(path), node.Value, message)}}
+}
+
+func validateStruct(node *yaml.Node, t reflect.Type, path string, file string) ValidationErrors {
+	fields := yamlFields(t)
+	errs := make(ValidationErrors, 0)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		field, ok := fields[key.Value]
+		if !ok {
+			message := fmt.Sprintf("unknown key %q in %s", key.Value, describePath(path))
+			if suggestion := closestKey(key.Value, fields); suggestion != "" {
+				message = fmt.Sprintf("%s, did you mean %q?", message, suggestion)
+			}
+			errs = append(errs, ValidationError{Origin{File: file, Line: key.Line, Column: key.Column}, message})
+			continue
+		}
+		errs = append(errs, validateNode(value, field.Type, joinPath(path, key.Value), file)...)
+	}
+	return errs
+}
+
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "configuration root"
+	}
+	return path
+}
+
+func closestKey(key string, fields map[string]reflect.StructField) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if distance := editDistance(key, name); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func (c *AppConfig) ValidateInputs() ValidationErrors {
+	errs := make(ValidationErrors, 0)
+	inputs := map[string]string{
+		"input.build_order":     c.Input.BuildOrder,
+		"input.module_registry": c.Input.ModuleRegistry,
+	}
+	for _, path := range []string{"input.build_order", "input.module_registry"} {
+		file := inputs[path]
+		if file == "" {
+			errs = append(errs, ValidationError{c.OriginOf(path), fmt.Sprintf("%s is not configured", path)})
+		} else if !fileExists(file) {
+			errs = append(errs, ValidationError{c.OriginOf(path), fmt.Sprintf("%s points to missing file %q", path, file)})
+		}
+	}
+	return errs
+}
+
+func (c *AppConfig) OriginOf(path string) Origin {
+	if origin, ok := c.Origins[path]; ok {
+		return origin
+	}
+	var nested *Origin
+	for key, origin := range c.Origins {
+		if strings.HasPrefix(key, path+".") && (nested == nil || origin.Line < nested.Line) {
+			nested = &origin
+		}
+	}
+	if nested != nil {
+		return *nested
+	}
+	return Origin{File: BuiltinOrigin}
+}
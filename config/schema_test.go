@@ -0,0 +1,63 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func Test_validateLayer(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "Should accept valid configuration",
+			content: "root: /opt\nfail_on_error: true\nmodules:\n  ModuleA:\n    timeout: 5m\n",
+			want:    []string{},
+		},
+		{
+			name:    "Should report unknown keys with suggestion",
+			content: "root: /opt\nfail_on_eror: true\ncommands:\n  comstom: {}\n",
+			want: []string{
+				`cfg.yml:2:1: unknown key "fail_on_eror" in configuration root, did you mean "fail_on_error"?`,
+				`cfg.yml:4:3: unknown key "comstom" in commands, did you mean "custom"?`,
+			},
+		},
+		{
+			name:    "Should report type mismatches",
+			content: "fail_on_error: sometimes\naliases: [a, b]\nmodules:\n  ModuleA:\n    timeout: soon\n",
+			want: []string{
+				"cfg.yml:1:16: fail_on_error has invalid value",
+				"cfg.yml:2:10: aliases must be a mapping",
+				"cfg.yml:5:14: modules.ModuleA.timeout must be a duration",
+			},
+		},
+		{
+			name:    "Should validate profiles",
+			content: "profiles:\n  ci:\n    rot: /ci\n",
+			want:    []string{`cfg.yml:3:5: unknown key "rot" in profiles.ci, did you mean "root"?`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := &yaml.Node{}
+			err := yaml.Unmarshal([]byte(tt.content), document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := validateLayer(configLayer{file: "cfg.yml", node: document.Content[0]})
+			if len(got) != len(tt.want) {
+				t.Errorf("validateLayer() = %v, want %v", got, tt.want)
+				return
+			}
+			for i, validationError := range got {
+				if !strings.HasPrefix(validationError.Error(), tt.want[i]) {
+					t.Errorf("validateLayer() error %d = %v, want prefix %v", i, validationError, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type SourceSet struct {
+	Symbol          string `yaml:"-"`
+	Directory       string
+	Clobberable     bool
+	RequiresRestart bool `yaml:"requires_restart"`
+	Order           int
+	Template        string `yaml:",omitempty"`
+}
+
+func DefaultSourceSets() map[string]SourceSet {
+	return map[string]SourceSet{
+		SrcSymbol:      {Directory: SRC, Clobberable: true, RequiresRestart: true, Order: 10},
+		SrcTestSymbol:  {Directory: SrcTest, Clobberable: true, Order: 20},
+		SrcWebSymbol:   {Directory: SrcWeb, Clobberable: false, Order: 30},
+		SeleniumSymbol: {Directory: SrcSelenium, Clobberable: true, Order: 40},
+		UpgradeSymbol:  {Directory: SrcUpgrade, Clobberable: true, RequiresRestart: true, Order: 50},
+		HybridSymbol:   {Directory: SrcHybrid, Clobberable: true, RequiresRestart: true, Order: 60},
+	}
+}
+
+func (c *AppConfig) SourceSet(symbol string) (SourceSet, bool) {
+	sourceSet, ok := c.SourceSets[symbol]
+	if !ok {
+		sourceSet, ok = DefaultSourceSets()[symbol]
+	}
+	sourceSet.Symbol = symbol
+	return sourceSet, ok
+}
+
+func (c *AppConfig) OrderedSourceSets() []SourceSet {
+	sourceSets := DefaultSourceSets()
+	for symbol, sourceSet := range c.SourceSets {
+		sourceSets[symbol] = sourceSet
+	}
+	result := make([]SourceSet, 0, len(sourceSets))
+	for symbol, sourceSet := range sourceSets {
+		sourceSet.Symbol = symbol
+		result = append(result, sourceSet)
+	}
+	slices.SortFunc(result, func(a, b SourceSet) int {
+		if a.Order != b.Order {
+			return a.Order - b.Order
+		}
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+	return result
+}
+
+func (c *AppConfig) validateSourceSets() error {
+	for symbol, sourceSet := range c.SourceSets {
+		if len(symbol) != 1 || symbol == ClobberSymbol || symbol == AllSourcesSymbol {
+			return fmt.Errorf("source set symbol %q must be a single character other than %q and %q", symbol, ClobberSymbol, AllSourcesSymbol)
+		}
+		if sourceSet.Directory == "" {
+			return fmt.Errorf("source set %q has no directory", symbol)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+const DefaultBuildFile = "build.xml"
+
+const BuildCommandTemplate = "ant -f {{.Module.Location}}/{{.Source}}/{{.BuildFile}}{{.Properties}}"
+const ClobberCommandTemplate = "ant clobber -f {{.Module.Location}}/{{.Source}}/{{.BuildFile}}{{.Properties}}"
+const TestCommandTemplate = "ant {{.Target}} -f {{.Module.Location}}/{{.Source}}/{{.BuildFile}}{{.Properties}}{{if .TestIncludes}} \"-Dtest.includes=**/{{.TestIncludes}}\"{{end}}"
+const NumKeyStartCommandTemplate = "ant -v -f /opt/wnc/tools_vs/build/commonUtils.xml darjeeling.start_dbserver"
+const NumKeyCommandTemplate = "ant -f {{.Module.Location}}/{{.Source}}/{{.BuildFile}} clean clobber all -Ddarjeeling.updnumkey=true{{.Properties}}"
+const NumKeyStopCommandTemplate = "ant -v -f /opt/wnc/tools_vs/build/commonUtils.xml darjeeling.stop_dbserver"
+
+type Templates struct {
+	Build       string
+	Clobber     string
+	Test        string
+	NumKeyStart string `yaml:"num_key_start"`
+	NumKey      string `yaml:"num_key"`
+	NumKeyStop  string `yaml:"num_key_stop"`
+}
+
+func DefaultTemplates() Templates {
+	return Templates{
+		Build:       BuildCommandTemplate,
+		Clobber:     ClobberCommandTemplate,
+		Test:        TestCommandTemplate,
+		NumKeyStart: NumKeyStartCommandTemplate,
+		NumKey:      NumKeyCommandTemplate,
+		NumKeyStop:  NumKeyStopCommandTemplate,
+	}
+}
+
+func (t Templates) Merge(override Templates) Templates {
+	if override.Build != "" {
+		t.Build = override.Build
+	}
+	if override.Clobber != "" {
+		t.Clobber = override.Clobber
+	}
+	if override.Test != "" {
+		t.Test = override.Test
+	}
+	if override.NumKeyStart != "" {
+		t.NumKeyStart = override.NumKeyStart
+	}
+	if override.NumKey != "" {
+		t.NumKey = override.NumKey
+	}
+	if override.NumKeyStop != "" {
+		t.NumKeyStop = override.NumKeyStop
+	}
+	return t
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+func runConfigCommand(appConfig *config.AppConfig, cmdArgs *config.ProgramArguments) error {
+	command := cmdArgs.ConfigCommand
+	switch {
+	case command.Validate != nil:
+		return validateConfig(appConfig)
+	case command.Show != nil:
+		return appConfig.WriteEffective(os.Stdout, command.Show.Origin)
+	}
+	return cmdArgs.WriteHelp("config")
+}
+
+func validateConfig(appConfig *config.AppConfig) error {
+	validationErrors := appConfig.ValidateInputs()
+	if len(validationErrors) == 0 {
+		moduleInfos, err := module.CalculateModuleInfo(appConfig)
+		if err != nil {
+			return err
+		}
+		validationErrors = append(validationErrors, module.ValidateReferences(appConfig, moduleInfos)...)
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	fmt.Printf("%s OK %s configuration loaded from %s is valid\n", config.OkColor, config.NoColor, strings.Join(appConfig.Files, ", "))
+	return nil
+}
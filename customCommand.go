@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"wnc_builder/config"
+)
+
+func runCustomCommand(appConfig *config.AppConfig, cmdArgs *config.ProgramArguments) error {
+	if cmdArgs.CustomCommands.List != nil {
+		return listCustomCommands(appConfig)
+	}
+	return cmdArgs.WriteHelp("custom")
+}
+
+func listCustomCommands(appConfig *config.AppConfig) error {
+	if len(appConfig.Commands.Custom) == 0 {
+		fmt.Println("no custom commands configured")
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "COMMAND\tPARAMETER\tTYPE\tDEFAULT\tHELP")
+	for _, name := range config.SortedKeys(appConfig.Commands.Custom) {
+		command := appConfig.Commands.Custom[name]
+		help := command.Help
+		if help == "" {
+			steps := make([]string, 0, len(command.Steps))
+			for _, step := range command.CommandSteps() {
+				steps = append(steps, strings.ReplaceAll(step.Command, "\n", " \\n "))
+			}
+			help = strings.Join(steps, "; ")
+		}
+		fmt.Fprintf(writer, "%s\t\t\t\t%s\n", name, help)
+		for _, param := range command.Params {
+			value := param.Default
+			if param.Required {
+				value = "(required)"
+			}
+			fmt.Fprintf(writer, "\t%s\t%s\t%s\t%s\n", param.Name, param.ParamType(), value, param.Help)
+		}
+	}
+	return writer.Flush()
+}
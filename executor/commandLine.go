@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"wnc_builder/config"
+)
+
+const argumentSpace = "\x00"
+
+var safeArgument = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+var variableAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+func newCommand(line string) *Command {
+	args, ok := splitCommandLine(line)
+	if !ok {
+		return &Command{Command: strings.ReplaceAll(line, argumentSpace, " ")}
+	}
+	for idx, arg := range args {
+		args[idx] = strings.ReplaceAll(arg, argumentSpace, " ")
+	}
+	return &Command{Command: quoteArgs(args), Args: args}
+}
+
+func shellOperators() string {
+	if runtime.GOOS == "windows" {
+		return "|&;<>()$`\n%^"
+	}
+	return "|&;<>()$`\n\\#*?["
+}
+
+func splitCommandLine(line string) ([]string, bool) {
+	args := make([]string, 0)
+	current := strings.Builder{}
+	inArg := false
+	quote := rune(0)
+	runes := []rune(line)
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && idx+1 < len(runes) && (runes[idx+1] == '"' || runes[idx+1] == '\\'):
+				idx++
+				current.WriteRune(runes[idx])
+			case r == '$' || r == '`':
+				return nil, false
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case strings.ContainsRune(shellOperators(), r), r == '~' && !inArg:
+			return nil, false
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, false
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 || variableAssignment.MatchString(args[0]) {
+		return nil, false
+	}
+	return args, true
+}
+
+func quoteArg(arg string) string {
+	if safeArgument.MatchString(arg) {
+		return arg
+	}
+	if runtime.GOOS == "windows" {
+		return quoteWindowsArg(arg)
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func quoteWindowsArg(arg string) string {
+	quoted := strings.Builder{}
+	quoted.WriteString(`"`)
+	backslashes := 0
+	for _, r := range arg {
+		switch r {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			quoted.WriteString(strings.Repeat(`\`, backslashes*2+1))
+		default:
+			quoted.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		backslashes = 0
+		quoted.WriteRune(r)
+	}
+	quoted.WriteString(strings.Repeat(`\`, backslashes*2))
+	quoted.WriteString(`"`)
+	return quoted.String()
+}
+
+func quoteArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, quoteArg(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+func lookPath(name string, env map[string]string) string {
+	path, ok := env[config.PathEnvVariable]
+	if !ok || strings.ContainsAny(name, `/\`) {
+		return name
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if found, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+			return found
+		}
+	}
+	return name
+}
@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_splitCommandLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   []string
+		wantOk bool
+	}{
+		{
+			name:   "Should split plain arguments",
+			line:   "ant -f /opt/ModuleA/src/build.xml  compile",
+			want:   []string{"ant", "-f", "/opt/ModuleA/src/build.xml", "compile"},
+			wantOk: true,
+		},
+		{
+			name:   "Should keep quoted arguments together",
+			line:   `ant "-Dtest.includes=**/My Test" '-Dname=it''s' "a \"b\""`,
+			want:   []string{"ant", "-Dtest.includes=**/My Test", "-Dname=its", `a "b"`},
+			wantOk: true,
+		},
+		{
+			name:   "Should restore protected spaces",
+			line:   "ant -f /opt/My" + argumentSpace + "Module/build.xml",
+			want:   []string{"ant", "-f", "/opt/My" + argumentSpace + "Module/build.xml"},
+			wantOk: true,
+		},
+		{
+			name: "Should need a shell for operators",
+			line: "windchill stop && windchill start",
+		},
+		{
+			name: "Should need a shell for expansions",
+			line: `echo "$HOME"`,
+		},
+		{
+			name: "Should need a shell for variable assignments",
+			line: "ANT_OPTS=-Xmx2g ant -f build.xml",
+		},
+		{
+			name: "Should need a shell for backslash escapes",
+			line: `ls a\ b`,
+		},
+		{
+			name: "Should need a shell for home expansion",
+			line: "ls ~/x",
+		},
+		{
+			name: "Should need a shell for globs",
+			line: "ant -lib lib/*.jar compile",
+		},
+		{
+			name:   "Should keep quoted globs literal",
+			line:   `ant "-Dtest.includes=**/My?Test[1]"`,
+			want:   []string{"ant", "-Dtest.includes=**/My?Test[1]"},
+			wantOk: true,
+		},
+		{
+			name: "Should need a shell for comments",
+			line: "ant compile # comment",
+		},
+		{
+			name:   "Should keep quoted special characters",
+			line:   `ant '-Dname=~/x#1' "a\\b"`,
+			want:   []string{"ant", "-Dname=~/x#1", `a\b`},
+			wantOk: true,
+		},
+		{
+			name: "Should need a shell for unterminated quotes",
+			line: "echo 'test",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := splitCommandLine(tt.line)
+			if ok != tt.wantOk || (ok && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("splitCommandLine() = %q %v, want %q %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_newCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		want     string
+		wantArgs []string
+	}{
+		{
+			name:     "Should quote arguments for printing",
+			line:     "ant -f /opt/My" + argumentSpace + "Module/build.xml \"-Dtest.includes=**/MyTest\"",
+			want:     "ant -f '/opt/My Module/build.xml' '-Dtest.includes=**/MyTest'",
+			wantArgs: []string{"ant", "-f", "/opt/My Module/build.xml", "-Dtest.includes=**/MyTest"},
+		},
+		{
+			name: "Should keep shell commands verbatim",
+			line: "cd /opt/My" + argumentSpace + "Module && ant",
+			want: "cd /opt/My Module && ant",
+		},
+		{
+			name:     "Should escape single quotes",
+			line:     `echo "it's"`,
+			want:     `echo 'it'\''s'`,
+			wantArgs: []string{"echo", "it's"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newCommand(tt.line)
+			if got.Command != tt.want || !reflect.DeepEqual(got.Args, tt.wantArgs) {
+				t.Errorf("newCommand() = %q %q, want %q %q", got.Command, got.Args, tt.want, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func Test_quoteWindowsArg(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{name: "Should quote spaces", arg: `C:\Program Files\ant`, want: `"C:\Program Files\ant"`},
+		{name: "Should escape quotes", arg: `say "hi"`, want: `"say \"hi\""`},
+		{name: "Should double trailing backslashes", arg: `C:\my dir\`, want: `"C:\my dir\\"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteWindowsArg(tt.arg); got != tt.want {
+				t.Errorf("quoteWindowsArg() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
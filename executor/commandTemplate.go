@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+type commandContext struct {
+	Module       *module.ModuleInfo
+	Source       string
+	Target       string
+	TestIncludes string
+	Root         string
+	BuildFile    string
+	Properties   string
+}
+
+func (tb *taskBuilder) newCommandContext(task Task) commandContext {
+	context := commandContext{
+		Module:    task.Module,
+		Target:    task.Target.String(),
+		Root:      tb.appConfig.Root,
+		BuildFile: config.DefaultBuildFile,
+	}
+	if task.Module != nil {
+		if task.Module.BuildFile != "" {
+			context.BuildFile = task.Module.BuildFile
+		}
+		context.Properties = formatProperties(task.Module.Properties)
+	}
+	return context
+}
+
+func (tb *taskBuilder) moduleTemplates(task Task) config.Templates {
+	templates := tb.appConfig.CommandTemplates()
+	if task.Module != nil {
+		templates = templates.Merge(task.Module.Templates)
+	}
+	return templates
+}
+
+func formatProperties(properties map[string]string) string {
+	result := strings.Builder{}
+	for _, key := range config.SortedKeys(properties) {
+		result.WriteString(fmt.Sprintf(" -D%s=%s", key, properties[key]))
+	}
+	return result.String()
+}
+
+func renderCommand(name string, commandTemplate string, context commandContext) (*Command, error) {
+	parsed, err := template.New(name).Option("missingkey=error").Parse(commandTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s command template. %w", name, err)
+	}
+	rendered := strings.Builder{}
+	err = parsed.Execute(&rendered, context.protected())
+	if err != nil {
+		return nil, fmt.Errorf("could not render %s command template. %w", name, err)
+	}
+	command := newCommand(rendered.String())
+	if context.Module != nil {
+		command.Env = context.Module.Env
+		command.Timeout = context.Module.Timeout
+	}
+	return command, nil
+}
+
+func (c commandContext) protected() commandContext {
+	protect := func(value string) string { return strings.ReplaceAll(value, " ", argumentSpace) }
+	c.Source = protect(c.Source)
+	c.TestIncludes = protect(c.TestIncludes)
+	c.Root = protect(c.Root)
+	c.BuildFile = protect(c.BuildFile)
+	if c.Module != nil {
+		moduleInfo := *c.Module
+		moduleInfo.Location = protect(moduleInfo.Location)
+		properties := make(map[string]string, len(moduleInfo.Properties))
+		for key, value := range moduleInfo.Properties {
+			properties[protect(key)] = protect(value)
+		}
+		c.Module = &moduleInfo
+		c.Properties = formatProperties(properties)
+	}
+	return c
+}
@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+type customSpec struct {
+	name       string
+	module     string
+	positional []string
+	named      map[string]string
+}
+
+func parseCustomSpec(spec string) customSpec {
+	name, arguments, _ := strings.Cut(spec, config.CustomArgumentsSeparator)
+	name, moduleId, _ := strings.Cut(name, config.CustomModuleSeparator)
+	result := customSpec{name: name, module: moduleId, named: make(map[string]string)}
+	if arguments == "" {
+		return result
+	}
+	for _, argument := range strings.Split(arguments, config.CustomArgumentSeparator) {
+		if key, value, ok := strings.Cut(argument, config.CustomNamedSeparator); ok {
+			result.named[key] = value
+		} else {
+			result.positional = append(result.positional, argument)
+		}
+	}
+	return result
+}
+
+func renderCustomCommand(name string, command string, values map[string]any) (string, error) {
+	if values == nil {
+		return command, nil
+	}
+	parsed, err := template.New(name).Option("missingkey=error").Parse(command)
+	if err != nil {
+		return "", fmt.Errorf("could not parse custom command %s template. %w", name, err)
+	}
+	rendered := strings.Builder{}
+	err = parsed.Execute(&rendered, values)
+	if err != nil && strings.Contains(err.Error(), fmt.Sprintf("map has no entry for key %q", config.CustomModuleKey)) {
+		return "", fmt.Errorf("custom command %s needs a module, use %s%s<module>", name, name, config.CustomModuleSeparator)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not render custom command %s template. %w", name, err)
+	}
+	return rendered.String(), nil
+}
+
+func (tb *taskBuilder) customValues(spec customSpec, command config.CustomCommand, moduleInfo *module.ModuleInfo) (map[string]any, error) {
+	values, err := tb.customArguments(spec, command)
+	if err != nil || (len(command.Params) == 0 && moduleInfo == nil && !command.Template) {
+		return nil, err
+	}
+	values[config.CustomRootKey] = tb.appConfig.Root
+	if moduleInfo != nil {
+		values[config.CustomModuleKey] = moduleInfo
+	}
+	return values, nil
+}
+
+func (tb *taskBuilder) customArguments(spec customSpec, command config.CustomCommand) (map[string]any, error) {
+	if len(command.Params) == 0 {
+		if len(spec.positional) > 0 || len(spec.named) > 0 {
+			return nil, fmt.Errorf("custom command %s takes no parameters", spec.name)
+		}
+		return make(map[string]any), nil
+	}
+	if len(spec.positional) > len(command.Params) {
+		return nil, fmt.Errorf("custom command %s takes %d parameters, got %d", spec.name, len(command.Params), len(spec.positional))
+	}
+	raw := make(map[string]string, len(command.Params))
+	for idx, value := range spec.positional {
+		raw[command.Params[idx].Name] = value
+	}
+	for key, value := range spec.named {
+		if _, ok := command.Param(key); !ok {
+			return nil, fmt.Errorf("custom command %s has no parameter %s", spec.name, key)
+		}
+		if _, ok := raw[key]; ok {
+			return nil, fmt.Errorf("custom command %s parameter %s is given twice", spec.name, key)
+		}
+		raw[key] = value
+	}
+
+	values := make(map[string]any, len(command.Params)+2)
+	for _, param := range command.Params {
+		value, ok := raw[param.Name]
+		if !ok {
+			if param.Required {
+				return nil, fmt.Errorf("custom command %s requires parameter %s", spec.name, param.Name)
+			}
+			value = param.Default
+		}
+		converted, err := tb.convertParam(param, value)
+		if err != nil {
+			return nil, fmt.Errorf("custom command %s parameter %s: %w", spec.name, param.Name, err)
+		}
+		values[param.Name] = converted
+	}
+	return values, nil
+}
+
+func (tb *taskBuilder) convertParam(param config.CustomParam, value string) (any, error) {
+	switch param.ParamType() {
+	case config.ParamInt:
+		if value == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(value)
+	case config.ParamBool:
+		if value == "" {
+			return false, nil
+		}
+		return strconv.ParseBool(value)
+	case config.ParamModule:
+		if value == "" {
+			return "", nil
+		}
+		moduleInfo, err := tb.findModuleById(value)
+		if err != nil {
+			return nil, err
+		}
+		return moduleInfo.Name, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+func Test_taskBuilder_createCustomCommands(t *testing.T) {
+	appConfig := &config.AppConfig{
+		Root:    "/opt/wt",
+		Aliases: map[string]string{"ma": "ModuleA"},
+		Commands: config.Commands{Custom: map[string]config.CustomCommand{
+			"pid":     {Command: "echo {{.pid}}"},
+			"root":    {Command: "echo {{.Root}}", Template: true},
+			"list":    {Command: "ls /srv/app.Modules"},
+			"bundles": {Command: "regen {{.Module.Name}} {{.Module.Location}} {{.Root}}", Template: true},
+			"release": {
+				Steps: []config.CustomStep{
+					{Name: "package", Command: "ant package -Dversion={{.version}}", Timeout: time.Minute},
+					{Command: "notify {{.version}}", ContinueOnError: true},
+				},
+				Params:  []config.CustomParam{{Name: "version", Default: "1.0"}},
+				Finally: true,
+			},
+			"deploy": {
+				Command: "deploy {{.module}}{{if .verbose}} -v{{end}} -n {{.count}} -r {{.Root}}",
+				Params: []config.CustomParam{
+					{Name: "module", Type: config.ParamModule, Required: true},
+					{Name: "verbose", Type: config.ParamBool},
+					{Name: "count", Type: config.ParamInt, Default: "1"},
+				},
+			},
+		}},
+	}
+	modulesConfig := map[string]*module.ModuleInfo{"ModuleA": {Name: "ModuleA", Location: "/opt/ModuleA"}}
+	tests := []struct {
+		name    string
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{name: "Should run plain command verbatim", spec: "pid", want: "echo {{.pid}}"},
+		{name: "Should render root when template is enabled", spec: "root", want: "echo /opt/wt"},
+		{name: "Should not need module for plain text", spec: "list", want: "ls /srv/app.Modules"},
+		{name: "Should reject arguments for plain command", spec: "pid:1", wantErr: true},
+		{name: "Should apply defaults", spec: "deploy:ma", want: "deploy ModuleA -n 1 -r /opt/wt"},
+		{name: "Should mix positional and named arguments", spec: "deploy:ModuleA,count=3,verbose=true", want: "deploy ModuleA -v -n 3 -r /opt/wt"},
+		{name: "Should require parameter", spec: "deploy:verbose=true", wantErr: true},
+		{name: "Should reject unknown parameter", spec: "deploy:ma,force=true", wantErr: true},
+		{name: "Should reject too many arguments", spec: "deploy:ma,true,1,2", wantErr: true},
+		{name: "Should reject invalid type", spec: "deploy:ma,count=many", wantErr: true},
+		{name: "Should reject unknown module", spec: "deploy:mb", wantErr: true},
+		{name: "Should render module", spec: "bundles@ma", want: "regen ModuleA /opt/ModuleA /opt/wt"},
+		{name: "Should render module with arguments", spec: "deploy@ma:ModuleA,count=2", want: "deploy ModuleA -n 2 -r /opt/wt"},
+		{name: "Should require module", spec: "bundles", wantErr: true},
+		{name: "Should render every step", spec: "release:2.0", want: "ant package -Dversion=2.0 && notify 2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &taskBuilder{appConfig: appConfig, modulesConfig: modulesConfig}
+			task := Task{Target: config.Custom, targets: tt.spec}
+			if moduleId := parseCustomSpec(tt.spec).module; moduleId != "" {
+				task.Module, _ = tb.findModuleById(moduleId)
+			}
+			got, err := tb.createCustomCommands(task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("createCustomCommands() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			rendered := make([]string, 0, len(got))
+			for _, command := range got {
+				rendered = append(rendered, command.Command)
+			}
+			if strings.Join(rendered, " && ") != tt.want {
+				t.Errorf("createCustomCommands() = %v, want %v", rendered, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskBuilder_createCustomCommands_steps(t *testing.T) {
+	appConfig := &config.AppConfig{Commands: config.Commands{Custom: map[string]config.CustomCommand{
+		"release": {Workdir: "dist", Steps: []config.CustomStep{
+			{Name: "package", Command: "ant package", Timeout: time.Minute},
+			{Command: "notify", Workdir: "tools", ContinueOnError: true, Finally: true},
+		}},
+	}}}
+	tb := &taskBuilder{appConfig: appConfig}
+	got, err := tb.createCustomCommands(Task{Target: config.Custom, targets: "release"})
+	if err != nil {
+		t.Fatalf("createCustomCommands() error = %v", err)
+	}
+	want := []*Command{
+		{Command: "ant package", Name: "package", Timeout: time.Minute, Dir: "dist"},
+		{Command: "notify", Dir: "tools", ContinueOnError: true, Finally: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("createCustomCommands() = %v, want %v", got, want)
+	}
+}
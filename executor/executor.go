@@ -1,27 +1,56 @@
 package executor
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 	"wnc_builder/config"
 	"wnc_builder/module"
 )
 
 type Command struct {
-	Command  string
-	Status   config.ExecutionStatus
-	Duration time.Duration
+	Command         string
+	Status          config.ExecutionStatus
+	Duration        time.Duration
+	Env             map[string]string
+	Timeout         time.Duration
+	Check           *config.ReadinessCheck
+	Shutdown        bool
+	Source          string
+	Note            string
+	RequiresRestart bool
+	Finally         bool
+	Name            string
+	ContinueOnError bool
+	Dir             string
+	Args            []string
 }
 
 type Task struct {
-	Commands []*Command
-	Target   config.Target
-	Module   *module.ModuleInfo
-	targets  string
+	Commands    []*Command
+	Target      config.Target
+	Module      *module.ModuleInfo
+	targets     string
+	autoRestart bool
+	hookFailed  bool
+}
+
+func (t *Task) Describe() string {
+	if t.Module != nil && t.Target != config.Custom {
+		return fmt.Sprintf("%s %s", t.Target, t.Module.DisplayName())
+	}
+	if t.targets != "" {
+		return fmt.Sprintf("%s %s", t.Target, t.targets)
+	}
+	return t.Target.String()
 }
 
 type Executor interface {
@@ -33,34 +62,184 @@ type Executor interface {
 type executor struct {
 	appConfig     *config.AppConfig
 	modulesConfig map[string]*module.ModuleInfo
+	interrupted   atomic.Bool
+	running       atomic.Pointer[exec.Cmd]
+	stopWaiting   atomic.Pointer[context.CancelFunc]
+	log           *os.File
 }
 
 func NewTaskExecutor(appConfig *config.AppConfig, modulesConfig map[string]*module.ModuleInfo) Executor {
-	executor := executor{
+	return &executor{
 		appConfig:     appConfig,
 		modulesConfig: modulesConfig,
 	}
-	return &executor
 }
 
+var errInterrupted = errors.New("execution interrupted")
+
+const interruptGracePeriod = 10 * time.Second
+
 func (e *executor) RunTasks(tasks []*Task) error {
-	for _, task := range tasks {
-		err := e.RunCommands(task)
-		if e.appConfig.FailOnError && err != nil {
-			return err
+	stop := e.handleInterrupt()
+	defer stop()
+	closeLog, err := e.openRunLog()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	failure := e.runRunHooks(e.appConfig.Hooks.BeforeRun, config.Running)
+	if failure != nil {
+		for _, task := range tasks {
+			skipCommands(task)
+		}
+		return failure
+	}
+	for i, task := range tasks {
+		if (failure != nil || e.interrupted.Load()) && !task.finalOnly() {
+			skipCommands(task)
+			continue
+		}
+		if task.autoRestart {
+			decideRestart(task, tasks[:i])
+		}
+		err := e.runTaskHooks(e.appConfig.Hooks.BeforeTask, task)
+		if err != nil {
+			skipCommands(task)
+			task.hookFailed = true
+		} else {
+			err = e.RunCommands(task)
+			if hookErr := e.runTaskHooks(e.appConfig.Hooks.AfterTask, task); hookErr != nil {
+				task.hookFailed = true
+				if err == nil {
+					err = hookErr
+				}
+			}
+		}
+		if task.Status() == config.Failed {
+			e.runTaskHooks(e.appConfig.Hooks.OnFailure, task)
+		}
+		if e.appConfig.FailOnError && err != nil && failure == nil {
+			failure = err
+		}
+	}
+	if failure == nil && e.interrupted.Load() {
+		failure = errInterrupted
+	}
+
+	status := config.Completed
+	if failure != nil || slices.ContainsFunc(tasks, func(task *Task) bool { return task.Status() == config.Failed }) {
+		status = config.Failed
+	}
+	if hookErr := e.runRunHooks(e.appConfig.Hooks.AfterRun, status); failure == nil && e.appConfig.FailOnError {
+		failure = hookErr
+	}
+	return failure
+}
+
+func skipCommands(task *Task) {
+	for _, command := range task.Commands {
+		if command.Status == config.Prepared {
+			command.Status = config.Skipped
 		}
 	}
-	return nil
 }
 
 func (e *executor) RunCommands(tasks *Task) error {
+	var failure error
 	for _, command := range tasks.Commands {
+		if (failure != nil || e.interrupted.Load()) && !command.Finally {
+			if command.Status == config.Prepared {
+				command.Status = config.Skipped
+			}
+			continue
+		}
 		err := e.runCommand(command)
-		if e.appConfig.FailOnError && err != nil {
-			return err
+		if e.appConfig.FailOnError && err != nil && failure == nil && !command.ContinueOnError {
+			failure = err
+		}
+	}
+	return failure
+}
+
+func (e *executor) handleInterrupt() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		if _, ok := <-signals; ok {
+			e.interrupted.Store(true)
+			signal.Stop(signals)
+			e.signalRunning(os.Interrupt)
+			if stop := e.stopWaiting.Load(); stop != nil {
+				(*stop)()
+			}
+			fmt.Println("Interrupted, running finally commands. Press Ctrl-C again to abort them.")
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+}
+
+func (e *executor) run(toBeRun *exec.Cmd) error {
+	err := toBeRun.Start()
+	if err != nil {
+		return err
+	}
+	e.running.Store(toBeRun)
+	defer e.running.Store(nil)
+	return toBeRun.Wait()
+}
+
+func (e *executor) waitReady(ready *readiness) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e.stopWaiting.Store(&cancel)
+	defer e.stopWaiting.Store(nil)
+	return ready.wait(ctx)
+}
+
+func (e *executor) signalRunning(sig os.Signal) {
+	running := e.running.Load()
+	if running == nil || running.Process == nil || runtime.GOOS == "windows" {
+		return
+	}
+	_ = running.Process.Signal(sig)
+}
+
+func (t *Task) finalOnly() bool {
+	for _, command := range t.Commands {
+		if !command.Finally {
+			return false
+		}
+	}
+	return len(t.Commands) > 0
+}
+
+func decideRestart(task *Task, previous []*Task) {
+	reasons := make([]string, 0)
+	for _, previousTask := range previous {
+		for _, command := range previousTask.Commands {
+			if !command.RequiresRestart || command.Status != config.Completed {
+				continue
+			}
+			reason := fmt.Sprintf("%s %s", previousTask.Module.Name, command.Source)
+			if !slices.Contains(reasons, reason) {
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+	for _, command := range task.Commands {
+		if len(reasons) == 0 {
+			command.Status = config.Skipped
+			command.Note = "no completed build touched a source set requiring restart"
+			fmt.Printf("Restart skipped, %s.\n", command.Note)
+		} else {
+			command.Note = "required by " + strings.Join(reasons, ", ")
+			fmt.Printf("Restart %s.\n", command.Note)
 		}
 	}
-	return nil
 }
 
 func (e *executor) PrintSummary(tasks []*Task) {
@@ -69,15 +248,31 @@ func (e *executor) PrintSummary(tasks []*Task) {
 
 	duration := time.Duration(0)
 	for _, task := range tasks {
+		fmt.Println(task.Describe())
 		for _, command := range task.Commands {
 			duration = duration + command.Duration
 			roundedDuration := roundDuration(command.Duration, time.Millisecond*10)
-			fmt.Printf("%s %s %s in %s - %s\n", command.Status.Color(), command.Status, config.NoColor, roundedDuration, strings.Replace(command.Command, "\n", " \\n ", -1))
+			fmt.Printf("%s %s %s in %s - %s%s\n", command.Status.Color(), command.Status, config.NoColor, roundedDuration, command.Label(), formatNote(command.Note))
 		}
 	}
 	fmt.Printf("\nTotal execution time: %s %s %s\n", config.OkColor, roundDuration(duration, time.Millisecond*10), config.NoColor)
 }
 
+func (c *Command) Label() string {
+	label := strings.Replace(c.Command, "\n", " \\n ", -1)
+	if c.Name != "" {
+		return c.Name + ": " + label
+	}
+	return label
+}
+
+func formatNote(note string) string {
+	if note == "" {
+		return ""
+	}
+	return " (" + note + ")"
+}
+
 func roundDuration(d time.Duration, precision time.Duration) time.Duration {
 	if precision <= 0 {
 		return d
@@ -94,32 +289,57 @@ func (e *executor) runCommand(command *Command) error {
 	command.Status = config.Running
 	start := time.Now()
 
-	toBeRun := e.prepareCommand(command)
-	toBeRun.Stdout = os.Stdout
-	toBeRun.Stderr = os.Stderr
-	err := toBeRun.Run()
+	var ready *readiness
+	if command.Check != nil && command.Check.Enabled() {
+		ready = newReadiness(command.Check, command.Shutdown)
+	}
+	e.logCommandContext(command)
+	ctx, cancel := commandContextWithTimeout(command)
+	defer cancel()
+	toBeRun := e.prepareCommand(ctx, command)
+	toBeRun.Stdout = e.stdout()
+	toBeRun.Stderr = e.stderr()
+	err := e.run(toBeRun)
+	if err == nil && ready != nil {
+		fmt.Fprintf(e.stdout(), "Waiting up to %s for %s.\n", command.Check.WaitTimeout(), ready.describe())
+		readyErr := e.waitReady(ready)
+		command.Duration = time.Since(start)
+		if readyErr != nil {
+			command.Status = config.Failed
+			fmt.Fprintf(e.stdout(), "Command %s failed readiness check: %s.\n", strings.Replace(command.Command, "\n", " \\n ", -1), readyErr)
+			if e.appConfig.FailOnError {
+				return readyErr
+			}
+			e.printFooter(command)
+			return nil
+		}
+	}
 
 	command.Duration = time.Since(start)
 	if err != nil {
 		command.Status = config.Failed
-		fmt.Printf("Command %s failed with code %s.\n", strings.Replace(command.Command, "\n", " \\n ", -1), toBeRun.Err)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			fmt.Fprintf(e.stdout(), "Command %s timed out after %s.\n", strings.Replace(command.Command, "\n", " \\n ", -1), command.Timeout)
+		} else {
+			fmt.Fprintf(e.stdout(), "Command %s failed with code %s.\n", strings.Replace(command.Command, "\n", " \\n ", -1), toBeRun.Err)
+		}
 		if e.appConfig.FailOnError {
 			return err
 		}
 	} else {
 		command.Status = config.Completed
-		fmt.Printf("Command %s completed successfully.\n", strings.Replace(command.Command, "\n", "\\n", -1))
+		fmt.Fprintf(e.stdout(), "Command %s completed successfully.\n", strings.Replace(command.Command, "\n", "\\n", -1))
 	}
 	e.printFooter(command)
 	return nil
 }
 
 func (e *executor) printHeader(command *Command) {
-	fmt.Println(strings.Repeat(config.CmdFiller, config.CommandSize))
+	fmt.Fprintln(e.stdout(), strings.Repeat(config.CmdFiller, config.CommandSize))
 	message := "Executing command " + command.Command
 	dashCombo := e.calculateFiller(len(message))
-	fmt.Println(strings.Join([]string{dashCombo, message, dashCombo}, " "))
-	fmt.Println(strings.Repeat(config.CmdFiller, config.CommandSize))
+	fmt.Fprintln(e.stdout(), strings.Join([]string{dashCombo, message, dashCombo}, " "))
+	fmt.Fprintln(e.stdout(), strings.Repeat(config.CmdFiller, config.CommandSize))
 }
 
 func (e *executor) calculateFiller(messageLen int) string {
@@ -132,10 +352,38 @@ func (e *executor) calculateFiller(messageLen int) string {
 
 func (e *executor) printFooter(command *Command) {}
 
-func (e *executor) prepareCommand(cmd *Command) *exec.Cmd {
-	if runtime.GOOS == "windows" {
-		return exec.Command("cmd", "/U", "/c", cmd.Command)
-	} else {
-		return exec.Command("sh", "-c", cmd.Command)
+func commandContextWithTimeout(command *Command) (context.Context, context.CancelFunc) {
+	if command.Timeout > 0 {
+		return context.WithTimeout(context.Background(), command.Timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+func (e *executor) prepareCommand(ctx context.Context, cmd *Command) *exec.Cmd {
+	var toBeRun *exec.Cmd
+	switch {
+	case len(cmd.Args) > 0:
+		toBeRun = exec.CommandContext(ctx, lookPath(cmd.Args[0], cmd.Env), cmd.Args[1:]...)
+	case runtime.GOOS == "windows":
+		toBeRun = exec.CommandContext(ctx, "cmd", "/U", "/c", cmd.Command)
+	default:
+		toBeRun = exec.CommandContext(ctx, "sh", "-c", cmd.Command)
+	}
+	if runtime.GOOS != "windows" {
+		toBeRun.Cancel = func() error { return toBeRun.Process.Signal(os.Interrupt) }
+		toBeRun.WaitDelay = interruptGracePeriod
+	}
+	if len(cmd.Env) > 0 {
+		toBeRun.Env = append(os.Environ(), formatEnv(cmd.Env)...)
+	}
+	toBeRun.Dir = cmd.Dir
+	return toBeRun
+}
+
+func formatEnv(env map[string]string) []string {
+	result := make([]string, 0, len(env))
+	for _, key := range config.SortedKeys(env) {
+		result = append(result, key+"="+env[key])
 	}
+	return result
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -297,3 +298,97 @@ func Test_roundDuration(t *testing.T) {
 		})
 	}
 }
+
+func Test_decideRestart(t *testing.T) {
+	moduleInfo := &module.ModuleInfo{Name: "ModuleA"}
+	tests := []struct {
+		name     string
+		builds   []*Command
+		want     config.ExecutionStatus
+		wantNote string
+	}{
+		{
+			name:     "Should skip after web only build",
+			builds:   []*Command{{Status: config.Completed, Source: config.SrcWeb}},
+			want:     config.Skipped,
+			wantNote: "no completed build touched a source set requiring restart",
+		},
+		{
+			name: "Should restart after server side build",
+			builds: []*Command{
+				{Status: config.Completed, Source: config.SRC, RequiresRestart: true},
+				{Status: config.Completed, Source: config.SRC, RequiresRestart: true},
+				{Status: config.Completed, Source: config.SrcWeb},
+			},
+			want:     config.Prepared,
+			wantNote: "required by ModuleA src",
+		},
+		{
+			name:     "Should skip after failed server side build",
+			builds:   []*Command{{Status: config.Failed, Source: config.SRC, RequiresRestart: true}},
+			want:     config.Skipped,
+			wantNote: "no completed build touched a source set requiring restart",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restart := &Command{Command: "restart"}
+			task := &Task{Target: config.Restart, Commands: []*Command{restart}, autoRestart: true}
+			previous := []*Task{{Target: config.Build, Module: moduleInfo, Commands: tt.builds}}
+			decideRestart(task, previous)
+			if restart.Status != tt.want || restart.Note != tt.wantNote {
+				t.Errorf("decideRestart() = %s %q, want %s %q", restart.Status, restart.Note, tt.want, tt.wantNote)
+			}
+		})
+	}
+}
+
+func Test_executor_RunTasks_finally(t *testing.T) {
+	tests := []struct {
+		name        string
+		failOnError bool
+		want        []config.ExecutionStatus
+		wantErr     bool
+	}{
+		{
+			name:        "Should run finally commands after failure",
+			failOnError: true,
+			want:        []config.ExecutionStatus{config.Completed, config.Failed, config.Completed, config.Skipped, config.Completed},
+			wantErr:     true,
+		},
+		{
+			name: "Should continue without fail on error",
+			want: []config.ExecutionStatus{config.Completed, config.Failed, config.Completed, config.Completed, config.Completed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			numKey := &Task{Target: config.NumKey, Commands: []*Command{
+				{Command: "true"},
+				{Command: "false"},
+				{Command: "true", Finally: true},
+			}}
+			custom := &Task{Target: config.Custom, Commands: []*Command{{Command: "true"}}}
+			cleanup := &Task{Target: config.Custom, Commands: []*Command{{Command: "true", Finally: true}}}
+			e := &executor{appConfig: &config.AppConfig{FailOnError: tt.failOnError}}
+
+			stdout := os.Stdout
+			os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			err := e.RunTasks([]*Task{numKey, custom, cleanup})
+			os.Stdout = stdout
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RunTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := make([]config.ExecutionStatus, 0, len(tt.want))
+			for _, task := range []*Task{numKey, custom, cleanup} {
+				for _, command := range task.Commands {
+					got = append(got, command.Status)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RunTasks() statuses = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
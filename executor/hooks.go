@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+	"wnc_builder/config"
+)
+
+const (
+	moduleEnvVariable = "WCB_MODULE"
+	targetEnvVariable = "WCB_TARGET"
+	statusEnvVariable = "WCB_STATUS"
+	logEnvVariable    = "WCB_LOG"
+)
+
+func (t *Task) Status() config.ExecutionStatus {
+	if t.hookFailed {
+		return config.Failed
+	}
+	status := config.Skipped
+	for _, command := range t.Commands {
+		switch {
+		case command.Status == config.Failed:
+			return config.Failed
+		case command.Status == config.Completed:
+			status = config.Completed
+		case command.Status != config.Skipped && status == config.Skipped:
+			status = command.Status
+		}
+	}
+	return status
+}
+
+func (e *executor) runTaskHooks(hooks []config.Hook, task *Task) error {
+	modules := make([]string, 0)
+	if task.Module != nil {
+		modules = append(modules, task.Module.Name)
+		modules = append(modules, task.Module.Aliases...)
+	}
+	for _, hook := range hooks {
+		if hook.Matches(task.Target, modules...) {
+			err := e.runHook(hook, task, task.Status())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *executor) runRunHooks(hooks []config.Hook, status config.ExecutionStatus) error {
+	for _, hook := range hooks {
+		err := e.runHook(hook, nil, status)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *executor) runHook(hook config.Hook, task *Task, status config.ExecutionStatus) error {
+	env := e.appConfig.Environment()
+	if task != nil {
+		env = e.appConfig.Environment(task.Target)
+		env[targetEnvVariable] = task.Target.String()
+		if task.Module != nil {
+			for key, value := range task.Module.Env {
+				env[key] = value
+			}
+			env[moduleEnvVariable] = task.Module.Name
+		}
+	}
+	env[statusEnvVariable] = status.String()
+	env[logEnvVariable] = e.logPath()
+	command := &Command{Command: hook.Command, Env: env}
+	fmt.Fprintf(e.stdout(), "Running hook %s\n", hook.Command)
+	toBeRun := e.prepareCommand(context.Background(), command)
+	toBeRun.Stdout = e.stdout()
+	toBeRun.Stderr = e.stderr()
+	err := toBeRun.Run()
+	if err != nil {
+		fmt.Fprintf(e.stdout(), "Hook %s failed with code %s.\n", hook.Command, err)
+		return fmt.Errorf("hook %s failed. %w", hook.Command, err)
+	}
+	return nil
+}
+
+func (e *executor) openRunLog() (func(), error) {
+	if e.appConfig.LogDir == "" {
+		return func() {}, nil
+	}
+	err := os.MkdirAll(e.appConfig.LogDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("could not create log directory %s. %w", e.appConfig.LogDir, err)
+	}
+	path := filepath.Join(e.appConfig.LogDir, "wcb-"+time.Now().Format("20060102-150405")+".log")
+	e.log, err = os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create run log %s. %w", path, err)
+	}
+	return func() {
+		e.log.Close()
+		e.log = nil
+	}, nil
+}
+
+func (e *executor) logCommandContext(command *Command) {
+	if e.log == nil {
+		return
+	}
+	dir := command.Dir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	fmt.Fprintf(e.log, "Working directory: %s\n", dir)
+	for _, variable := range formatEnv(command.Env) {
+		fmt.Fprintf(e.log, "Environment: %s\n", variable)
+	}
+}
+
+func (e *executor) logPath() string {
+	if e.log == nil {
+		return ""
+	}
+	return e.log.Name()
+}
+
+func (e *executor) stdout() io.Writer {
+	if e.log == nil {
+		return os.Stdout
+	}
+	return io.MultiWriter(os.Stdout, e.log)
+}
+
+func (e *executor) stderr() io.Writer {
+	if e.log == nil {
+		return os.Stderr
+	}
+	return io.MultiWriter(os.Stderr, e.log)
+}
@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+func Test_executor_RunTasks_hooks(t *testing.T) {
+	moduleInfo := &module.ModuleInfo{Name: "ModuleA", Aliases: []string{"ma"}}
+	tests := []struct {
+		name      string
+		hooks     config.Hooks
+		commands  []string
+		keepGoing bool
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "Should run hooks with context",
+			hooks: config.Hooks{
+				BeforeRun:  config.HookList{{Command: `echo before_run $WCB_STATUS`}},
+				BeforeTask: config.HookList{{Command: `echo before_task $WCB_TARGET $WCB_MODULE $WCB_STATUS`, Target: config.StringList{"build"}}},
+				AfterTask:  config.HookList{{Command: `echo after_task $WCB_STATUS`, Module: config.StringList{"ma"}}},
+				AfterRun:   config.HookList{{Command: `echo after_run $WCB_STATUS`}},
+			},
+			commands: []string{"true"},
+			want:     []string{"before_run RUNNING", "before_task build ModuleA PREPARED", "after_task COMPLETED", "after_run COMPLETED"},
+		},
+		{
+			name: "Should skip hooks not matching filters",
+			hooks: config.Hooks{
+				BeforeTask: config.HookList{{Command: `echo before_task`, Target: config.StringList{"num_key"}}},
+				AfterTask:  config.HookList{{Command: `echo after_task`, Module: config.StringList{"other"}}},
+			},
+			commands: []string{"true"},
+			want:     []string{},
+		},
+		{
+			name: "Should run failure hooks",
+			hooks: config.Hooks{
+				OnFailure: config.HookList{{Command: `echo on_failure $WCB_MODULE $WCB_STATUS`}},
+				AfterRun:  config.HookList{{Command: `echo after_run $WCB_STATUS`}},
+			},
+			commands: []string{"false"},
+			want:     []string{"on_failure ModuleA FAILED", "after_run FAILED"},
+			wantErr:  true,
+		},
+		{
+			name: "Should skip task when before hook fails",
+			hooks: config.Hooks{
+				BeforeTask: config.HookList{{Command: `false`}},
+				OnFailure:  config.HookList{{Command: `echo on_failure $WCB_STATUS`}},
+				AfterRun:   config.HookList{{Command: `echo after_run $WCB_STATUS`}},
+			},
+			commands: []string{"echo command"},
+			want:     []string{"on_failure FAILED", "after_run FAILED"},
+			wantErr:  true,
+		},
+		{
+			name: "Should fail run when before hook fails without fail on error",
+			hooks: config.Hooks{
+				BeforeTask: config.HookList{{Command: `false`}},
+				OnFailure:  config.HookList{{Command: `echo on_failure $WCB_STATUS`}},
+				AfterRun:   config.HookList{{Command: `echo after_run $WCB_STATUS`}},
+			},
+			commands:  []string{"echo command"},
+			keepGoing: true,
+			want:      []string{"on_failure FAILED", "after_run FAILED"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logDir := t.TempDir()
+			commands := make([]*Command, 0, len(tt.commands))
+			for _, command := range tt.commands {
+				commands = append(commands, &Command{Command: command})
+			}
+			task := &Task{Target: config.Build, Module: moduleInfo, Commands: commands}
+			e := &executor{appConfig: &config.AppConfig{FailOnError: !tt.keepGoing, LogDir: logDir, Hooks: tt.hooks}}
+
+			stdout := os.Stdout
+			os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			err := e.RunTasks([]*Task{task})
+			os.Stdout = stdout
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RunTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			logs, _ := filepath.Glob(filepath.Join(logDir, "*.log"))
+			if len(logs) != 1 {
+				t.Fatalf("RunTasks() logs = %v, want one run log", logs)
+			}
+			content, _ := os.ReadFile(logs[0])
+			got := make([]string, 0)
+			for _, line := range strings.Split(string(content), "\n") {
+				for _, prefix := range []string{"before_", "after_", "on_failure", "command"} {
+					if strings.HasPrefix(line, prefix) {
+						got = append(got, line)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RunTasks() hook output = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_executor_RunTasks_beforeRunFailure(t *testing.T) {
+	command := &Command{Command: "true"}
+	e := &executor{appConfig: &config.AppConfig{LogDir: t.TempDir(), Hooks: config.Hooks{BeforeRun: config.HookList{{Command: "false"}}}}}
+
+	stdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	err := e.RunTasks([]*Task{{Target: config.Build, Commands: []*Command{command}}})
+	os.Stdout = stdout
+
+	if err == nil || command.Status != config.Skipped {
+		t.Errorf("RunTasks() error = %v, status = %s, want error and %s", err, command.Status, config.Skipped)
+	}
+}
@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"wnc_builder/config"
+)
+
+type readiness struct {
+	check    *config.ReadinessCheck
+	shutdown bool
+	pattern  *regexp.Regexp
+	offset   int64
+}
+
+func newReadiness(check *config.ReadinessCheck, shutdown bool) *readiness {
+	r := &readiness{check: check, shutdown: shutdown}
+	if check.Log != "" {
+		r.pattern = regexp.MustCompile(check.Pattern)
+		if info, err := os.Stat(check.Log); err == nil {
+			r.offset = info.Size()
+		}
+	}
+	return r
+}
+
+func (r *readiness) describe() string {
+	probes := make([]string, 0, 3)
+	if r.check.URL != "" {
+		probes = append(probes, "url "+r.check.URL)
+	}
+	if r.check.Address != "" {
+		probes = append(probes, "address "+r.check.Address)
+	}
+	if r.check.Log != "" {
+		probes = append(probes, fmt.Sprintf("pattern %q in %s", r.check.Pattern, r.check.Log))
+	}
+	if r.shutdown {
+		return "shutdown of " + strings.Join(probes, ", ")
+	}
+	return strings.Join(probes, ", ")
+}
+
+func (r *readiness) wait(ctx context.Context) error {
+	deadline := time.Now().Add(r.check.WaitTimeout())
+	for {
+		pending := r.pending()
+		if pending == "" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if r.shutdown {
+				return fmt.Errorf("%s still up after %s", pending, r.check.WaitTimeout())
+			}
+			return fmt.Errorf("%s not ready after %s", pending, r.check.WaitTimeout())
+		}
+		select {
+		case <-ctx.Done():
+			return errInterrupted
+		case <-time.After(r.check.PollInterval()):
+		}
+	}
+}
+
+func (r *readiness) pending() string {
+	if r.check.URL != "" && r.urlReady() == r.shutdown {
+		return "url " + r.check.URL
+	}
+	if r.check.Address != "" && r.addressReady() == r.shutdown {
+		return "address " + r.check.Address
+	}
+	if r.check.Log != "" && !r.logReady() {
+		return fmt.Sprintf("pattern %q in %s", r.check.Pattern, r.check.Log)
+	}
+	return ""
+}
+
+func (r *readiness) urlReady() bool {
+	client := http.Client{Timeout: r.check.PollInterval()}
+	response, err := client.Get(r.check.URL)
+	if err != nil {
+		return false
+	}
+	response.Body.Close()
+	return response.StatusCode < http.StatusInternalServerError
+}
+
+func (r *readiness) addressReady() bool {
+	connection, err := net.DialTimeout("tcp", r.check.Address, r.check.PollInterval())
+	if err != nil {
+		return false
+	}
+	connection.Close()
+	return true
+}
+
+func (r *readiness) logReady() bool {
+	file, err := os.Open(r.check.Log)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.Size() < r.offset {
+		r.offset = 0
+	}
+	_, err = file.Seek(r.offset, 0)
+	if err != nil {
+		return false
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if r.pattern.MatchString(scanner.Text()) {
+			return true
+		}
+	}
+	return false
+}
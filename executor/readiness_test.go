@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wnc_builder/config"
+)
+
+func Test_readiness_wait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	logFile := filepath.Join(t.TempDir(), "MethodServer.log")
+	err = os.WriteFile(logFile, []byte("MethodServer ready\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		check    config.ReadinessCheck
+		shutdown bool
+		appended string
+		wantErr  bool
+	}{
+		{
+			name:  "Should accept responding url",
+			check: config.ReadinessCheck{URL: server.URL + "/Windchill"},
+		},
+		{
+			name:    "Should time out on server error",
+			check:   config.ReadinessCheck{URL: server.URL + "/down"},
+			wantErr: true,
+		},
+		{
+			name:  "Should accept open port",
+			check: config.ReadinessCheck{Address: listener.Addr().String()},
+		},
+		{
+			name:    "Should time out on closed port",
+			check:   config.ReadinessCheck{Address: closedAddress},
+			wantErr: true,
+		},
+		{
+			name:     "Should accept closed port on shutdown",
+			check:    config.ReadinessCheck{Address: closedAddress},
+			shutdown: true,
+		},
+		{
+			name:     "Should time out on open port on shutdown",
+			check:    config.ReadinessCheck{Address: listener.Addr().String()},
+			shutdown: true,
+			wantErr:  true,
+		},
+		{
+			name:    "Should ignore log lines written before the command",
+			check:   config.ReadinessCheck{Log: logFile, Pattern: "MethodServer ready"},
+			wantErr: true,
+		},
+		{
+			name:     "Should accept log pattern written after the command",
+			check:    config.ReadinessCheck{Log: logFile, Pattern: "MethodServer ready"},
+			appended: "2024 MethodServer ready\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check.Timeout = 50 * time.Millisecond
+			tt.check.Interval = 10 * time.Millisecond
+			ready := newReadiness(&tt.check, tt.shutdown)
+			if tt.appended != "" {
+				file, _ := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+				file.WriteString(tt.appended)
+				file.Close()
+			}
+			if err := ready.wait(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("wait() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_executor_waitReady_interrupted(t *testing.T) {
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := closed.Addr().String()
+	closed.Close()
+	e := &executor{}
+	ready := newReadiness(&config.ReadinessCheck{Address: address, Timeout: time.Minute, Interval: 10 * time.Millisecond}, false)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		(*e.stopWaiting.Load())()
+	}()
+	start := time.Now()
+	if err := e.waitReady(ready); err != errInterrupted || time.Since(start) > time.Second {
+		t.Errorf("waitReady() error = %v after %s, want %v", err, time.Since(start), errInterrupted)
+	}
+}
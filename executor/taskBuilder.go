@@ -3,6 +3,8 @@ package executor
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 	"wnc_builder/config"
 	"wnc_builder/module"
@@ -14,7 +16,7 @@ type taskBuilder struct {
 }
 
 type TaskBuilder interface {
-	BuildTasks(arguments *config.ProgramArguments) ([]*Task, error)
+	BuildTasks(arguments *config.RunCommand) ([]*Task, error)
 }
 
 func NewTaskBuilder(appConfig *config.AppConfig, modulesConfig map[string]*module.ModuleInfo) TaskBuilder {
@@ -25,23 +27,104 @@ func NewTaskBuilder(appConfig *config.AppConfig, modulesConfig map[string]*modul
 	return &builder
 }
 
-func (tb *taskBuilder) buildExplicitTasks(arguments *config.ProgramArguments) ([]*Task, error) {
+func (tb *taskBuilder) buildExplicitTasks(arguments *config.RunCommand) ([]*Task, error) {
 	tasks := make([]*Task, 0, 1)
+	lifecycleTasks, err := tb.createLifecycleTasks(arguments, config.Stop, config.ClearCache)
+	if err != nil {
+		return nil, err
+	}
+	tasks = append(tasks, lifecycleTasks...)
 	if arguments.Build != nil && len(arguments.Build) > 0 {
 		for _, moduleSpec := range arguments.Build {
-			moduleInfo, targets, err := tb.getTaskSpec(moduleSpec)
+			specs, err := tb.getModuleSetSpec(moduleSpec)
 			if err != nil {
 				return nil, err
 			}
+			for _, spec := range specs {
+				moduleInfo := spec.module
+				moduleTargets := tb.expandTargets(moduleInfo, spec.targets)
+				if spec.fromSet {
+					moduleTargets = tb.availableTargets(moduleInfo, moduleTargets)
+					if strings.Trim(moduleTargets, config.ClobberSymbol) == "" {
+						continue
+					}
+				}
+				err = tb.validateBuildSpec(moduleInfo, moduleTargets)
+				if err != nil {
+					return nil, err
+				}
+				task := Task{
+					Target:  config.Build,
+					Module:  moduleInfo,
+					targets: moduleTargets,
+				}
+				task.Commands, err = tb.createBuildCommands(task)
+				if err != nil {
+					return nil, err
+				}
+				tasks = append(tasks, &task)
+			}
+		}
+	}
+	testTasks, err := tb.createTestTasks(arguments)
+	if err != nil {
+		return nil, err
+	}
+	testsAfterServer := arguments.Start || arguments.Lifecycle(config.Restart)
+	if !testsAfterServer {
+		tasks = append(tasks, testTasks...)
+	}
+	if arguments.Custom != nil && len(arguments.Custom) > 0 {
+		for _, task := range arguments.Custom {
 			task := Task{
-				Target:  config.Build,
+				Target:  config.Custom,
+				targets: task,
+			}
+			if moduleId := parseCustomSpec(task.targets).module; moduleId != "" {
+				task.Module, err = tb.findModuleById(moduleId)
+				if err != nil {
+					return nil, err
+				}
+			}
+			commands, err := tb.createCustomCommands(task)
+			if err != nil {
+				return nil, err
+			}
+			task.Commands = commands
+			tasks = append(tasks, &task)
+		}
+	}
+	if arguments.NumKey != nil && len(arguments.NumKey) > 0 {
+		for _, task := range arguments.NumKey {
+			moduleInfo, _, err := tb.getTaskSpec(task)
+			if err != nil {
+				return nil, err
+			}
+			task := Task{
+				Target:  config.NumKey,
+				targets: task,
 				Module:  moduleInfo,
-				targets: targets,
 			}
-			task.Commands = tb.createBuildCommands(task)
+			task.Commands, err = tb.createNumKeyCommands(task)
+			if err != nil {
+				return nil, err
+			}
 			tasks = append(tasks, &task)
 		}
 	}
+	lifecycleTasks, err = tb.createLifecycleTasks(arguments, config.RebuildClient, config.Start, config.Restart, config.Status)
+	if err != nil {
+		return nil, err
+	}
+	tasks = append(tasks, lifecycleTasks...)
+	if testsAfterServer {
+		tasks = append(tasks, testTasks...)
+	}
+	return tasks, nil
+}
+
+func (tb *taskBuilder) createTestTasks(arguments *config.RunCommand) ([]*Task, error) {
+	tasks := make([]*Task, 0)
 	if arguments.TestUnit != nil && len(arguments.TestUnit) > 0 {
 		for _, moduleSpec := range arguments.TestUnit {
 			moduleInfo, targets, err := tb.getTaskSpec(moduleSpec)
@@ -53,9 +136,12 @@ func (tb *taskBuilder) buildExplicitTasks(arguments *config.ProgramArguments) ([
 				Module:  moduleInfo,
 				targets: targets,
 			}
-			task.Commands = []*Command{tb.createTestCommands(task)}
+			command, err := tb.createTestCommands(task)
+			if err != nil {
+				return nil, err
+			}
+			task.Commands = []*Command{command}
 			tasks = append(tasks, &task)
-
 		}
 	}
 	if arguments.TestIntegration != nil && len(arguments.TestIntegration) > 0 {
@@ -69,9 +155,12 @@ func (tb *taskBuilder) buildExplicitTasks(arguments *config.ProgramArguments) ([
 				Module:  moduleInfo,
 				targets: targets,
 			}
-			task.Commands = []*Command{tb.createTestCommands(task)}
+			command, err := tb.createTestCommands(task)
+			if err != nil {
+				return nil, err
+			}
+			task.Commands = []*Command{command}
 			tasks = append(tasks, &task)
-
 		}
 	}
 	if arguments.TestSelenium != nil && len(arguments.TestSelenium) > 0 {
@@ -85,18 +174,7 @@ func (tb *taskBuilder) buildExplicitTasks(arguments *config.ProgramArguments) ([
 				Module:  moduleInfo,
 				targets: targets,
 			}
-			task.Commands = []*Command{tb.createTestCommands(task)}
-			tasks = append(tasks, &task)
-
-		}
-	}
-	if arguments.Custom != nil && len(arguments.Custom) > 0 {
-		for _, task := range arguments.Custom {
-			task := Task{
-				Target:  config.Custom,
-				targets: task,
-			}
-			command, err := tb.createCustomCommands(task)
+			command, err := tb.createTestCommands(task)
 			if err != nil {
 				return nil, err
 			}
@@ -104,29 +182,6 @@ func (tb *taskBuilder) buildExplicitTasks(arguments *config.ProgramArguments) ([
 			tasks = append(tasks, &task)
 		}
 	}
-	if arguments.NumKey != nil && len(arguments.NumKey) > 0 {
-		for _, task := range arguments.NumKey {
-			moduleInfo, _, err := tb.getTaskSpec(task)
-			if err != nil {
-				return nil, err
-			}
-			task := Task{
-				Target:  config.NumKey,
-				targets: task,
-				Module:  moduleInfo,
-			}
-			command := tb.createNumKeyCommand(task)
-			task.Commands = []*Command{command}
-			tasks = append(tasks, &task)
-		}
-	}
-	if arguments.Restart {
-		task := Task{
-			Target:   config.Restart,
-			Commands: []*Command{{Command: tb.appConfig.Commands.OOTB.Restart}},
-		}
-		tasks = append(tasks, &task)
-	}
 	return tasks, nil
 }
 
@@ -144,6 +199,201 @@ func (tb *taskBuilder) getTaskSpec(moduleSpec string) (*module.ModuleInfo, strin
 	return definedModule, targets, nil
 }
 
+type moduleTarget struct {
+	module  *module.ModuleInfo
+	targets string
+	fromSet bool
+}
+
+func (tb *taskBuilder) getModuleSetSpec(moduleSpec string) ([]moduleTarget, error) {
+	return tb.resolveModuleSpec(moduleSpec, nil)
+}
+
+func (tb *taskBuilder) resolveModuleSpec(moduleSpec string, groups []string) ([]moduleTarget, error) {
+	if !strings.HasPrefix(moduleSpec, config.ModuleSetPrefix) {
+		moduleInfo, targets, err := tb.getTaskSpec(moduleSpec)
+		if err != nil {
+			return nil, err
+		}
+		return []moduleTarget{{module: moduleInfo, targets: targets}}, nil
+	}
+	selector := strings.TrimPrefix(moduleSpec, config.ModuleSetPrefix)
+	targets := ""
+	if _, isGroup := tb.appConfig.Groups[selector]; !isGroup {
+		if idx := strings.LastIndex(selector, "_"); idx >= 0 {
+			selector, targets = selector[:idx], selector[idx+1:]
+		}
+	}
+	switch {
+	case selector == config.AllModulesSet:
+		return withTargets(tb.orderedModules(func(info *module.ModuleInfo) bool { return true }), targets), nil
+	case strings.HasPrefix(selector, config.RangeModulesSet):
+		moduleInfos, err := tb.moduleRange(strings.TrimPrefix(selector, config.RangeModulesSet))
+		return withTargets(moduleInfos, targets), err
+	}
+	if _, isGroup := tb.appConfig.Groups[selector]; isGroup {
+		return tb.resolveGroup(selector, targets, groups)
+	}
+	return nil, fmt.Errorf("module set %s not supported", moduleSpec)
+}
+
+func (tb *taskBuilder) resolveGroup(group string, targets string, groups []string) ([]moduleTarget, error) {
+	if slices.Contains(groups, group) {
+		return nil, fmt.Errorf("module group cycle: %s", strings.Join(append(groups, group), " -> "))
+	}
+	result := make([]moduleTarget, 0)
+	for _, member := range tb.appConfig.Groups[group] {
+		resolved, err := tb.resolveModuleSpec(member, append(groups, group))
+		if err != nil {
+			return nil, fmt.Errorf("in module group %s: %w", group, err)
+		}
+		for _, member := range resolved {
+			if slices.ContainsFunc(result, func(existing moduleTarget) bool { return existing.module == member.module }) {
+				continue
+			}
+			member.fromSet = true
+			if targets != "" {
+				member.targets = targets
+			} else if member.targets == "" {
+				member.targets = config.AllSourcesSymbol
+			}
+			result = append(result, member)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b moduleTarget) int {
+		if a.module.InBuildOrder() != b.module.InBuildOrder() {
+			return b.module.Order - a.module.Order
+		}
+		return a.module.Order - b.module.Order
+	})
+	return result, nil
+}
+
+func withTargets(moduleInfos []*module.ModuleInfo, targets string) []moduleTarget {
+	if targets == "" {
+		targets = config.AllSourcesSymbol
+	}
+	result := make([]moduleTarget, 0, len(moduleInfos))
+	for _, moduleInfo := range moduleInfos {
+		result = append(result, moduleTarget{module: moduleInfo, targets: targets, fromSet: true})
+	}
+	return result
+}
+
+func (tb *taskBuilder) moduleRange(spec string) ([]*module.ModuleInfo, error) {
+	bounds := strings.Split(spec, config.RangeSeparator)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("module range %q must have form First%sLast", spec, config.RangeSeparator)
+	}
+	first, err := tb.findModuleById(bounds[0])
+	if err != nil {
+		return nil, err
+	}
+	last, err := tb.findModuleById(bounds[1])
+	if err != nil {
+		return nil, err
+	}
+	for _, bound := range []*module.ModuleInfo{first, last} {
+		if !bound.InBuildOrder() {
+			return nil, fmt.Errorf("module %s is not part of the build order", bound.DisplayName())
+		}
+	}
+	if first.Order > last.Order {
+		return nil, fmt.Errorf("module %s is built after %s, range is empty", first.DisplayName(), last.DisplayName())
+	}
+	return tb.orderedModules(func(info *module.ModuleInfo) bool {
+		return info.Order >= first.Order && info.Order <= last.Order
+	}), nil
+}
+
+func (tb *taskBuilder) orderedModules(filter func(info *module.ModuleInfo) bool) []*module.ModuleInfo {
+	result := make([]*module.ModuleInfo, 0, len(tb.modulesConfig))
+	for _, moduleInfo := range tb.modulesConfig {
+		if moduleInfo.InBuildOrder() && filter(moduleInfo) {
+			result = append(result, moduleInfo)
+		}
+	}
+	slices.SortFunc(result, func(a, b *module.ModuleInfo) int {
+		return a.Order - b.Order
+	})
+	return result
+}
+
+func (tb *taskBuilder) expandTargets(moduleInfo *module.ModuleInfo, targets string) string {
+	if !strings.Contains(targets, config.AllSourcesSymbol) {
+		return targets
+	}
+	symbols := make([]string, 0)
+	clobberable := false
+	for _, sourceSet := range tb.appConfig.OrderedSourceSets() {
+		if slices.Contains(moduleInfo.Sources, sourceSet.Directory) && !moduleInfo.SourceDisabled(sourceSet) {
+			symbols = append(symbols, sourceSet.Symbol)
+			clobberable = clobberable || sourceSet.Clobberable
+		}
+	}
+	expanded := strings.ReplaceAll(targets, config.AllSourcesSymbol, strings.Join(symbols, ""))
+	if !clobberable || moduleInfo.NoClobber {
+		expanded = strings.ReplaceAll(expanded, config.ClobberSymbol, "")
+	}
+	return expanded
+}
+
+func (tb *taskBuilder) availableTargets(moduleInfo *module.ModuleInfo, targets string) string {
+	available := make([]string, 0)
+	clobberable := false
+	for _, symbol := range strings.Split(targets, "") {
+		if symbol == config.ClobberSymbol {
+			continue
+		}
+		sourceSet, known := tb.appConfig.SourceSet(symbol)
+		if known && (moduleInfo.SourceDisabled(sourceSet) || !slices.Contains(moduleInfo.Sources, sourceSet.Directory)) {
+			continue
+		}
+		available = append(available, symbol)
+		clobberable = clobberable || sourceSet.Clobberable
+	}
+	if strings.Contains(targets, config.ClobberSymbol) && clobberable && !moduleInfo.NoClobber {
+		available = append(available, config.ClobberSymbol)
+	}
+	return strings.Join(available, "")
+}
+
+func (tb *taskBuilder) validateBuildSpec(moduleInfo *module.ModuleInfo, targets string) error {
+	problems := make([]string, 0)
+	clobberable := false
+	for _, symbol := range strings.Split(targets, "") {
+		if symbol == config.ClobberSymbol {
+			continue
+		}
+		sourceSet, known := tb.appConfig.SourceSet(symbol)
+		if !known {
+			problems = append(problems, fmt.Sprintf("unknown source symbol %q", symbol))
+			continue
+		}
+		clobberable = clobberable || sourceSet.Clobberable
+		if moduleInfo.SourceDisabled(sourceSet) {
+			problems = append(problems, fmt.Sprintf("source set %s (%q) is disabled", sourceSet.Directory, symbol))
+		} else if !slices.Contains(moduleInfo.Sources, sourceSet.Directory) {
+			problems = append(problems, fmt.Sprintf("source directory %s (%q) does not exist", sourceSet.Directory, symbol))
+		}
+	}
+	if strings.Contains(targets, config.ClobberSymbol) && !clobberable {
+		problems = append(problems, fmt.Sprintf("%q requested without any clobberable source", config.ClobberSymbol))
+	}
+	if strings.Contains(targets, config.ClobberSymbol) && moduleInfo.NoClobber {
+		problems = append(problems, "module must not be clobbered")
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("invalid build targets %q for module %s: %s", targets, moduleInfo.DisplayName(), strings.Join(problems, ", "))
+	if tb.appConfig.WarnOnInvalidSpec() {
+		fmt.Printf("%s WARNING %s %s\n", config.WarningColor, config.NoColor, message)
+		return nil
+	}
+	return errors.New(message)
+}
+
 func (tb *taskBuilder) findModuleById(id string) (*module.ModuleInfo, error) {
 	moduleName := tb.appConfig.Aliases[id]
 	if moduleName == "" {
@@ -154,61 +404,171 @@ func (tb *taskBuilder) findModuleById(id string) (*module.ModuleInfo, error) {
 			return nil, errors.New(fmt.Sprintf("Module alias %s not found.", id))
 		}
 	}
-	return tb.modulesConfig[moduleName], nil
+	aliasedModule := tb.modulesConfig[moduleName]
+	if aliasedModule == nil {
+		return nil, errors.New(fmt.Sprintf("Module %s of alias %s not found in module registry.", moduleName, id))
+	}
+	return aliasedModule, nil
 }
 
-func (tb *taskBuilder) BuildTasks(arguments *config.ProgramArguments) ([]*Task, error) {
-	return tb.buildExplicitTasks(arguments)
+func (tb *taskBuilder) BuildTasks(arguments *config.RunCommand) ([]*Task, error) {
+	tasks, err := tb.buildExplicitTasks(arguments)
+	if err != nil {
+		return nil, err
+	}
+	tb.applyEnvironment(tasks)
+	return tasks, nil
 }
 
-func (tb *taskBuilder) createBuildCommands(task Task) []*Command {
-	commands := make([]*Command, 0, 5)
-	for key, value := range config.ClobberableSources {
-		if strings.Contains(task.targets, key) {
-			if strings.Contains(task.targets, config.ClobberSymbol) {
-				command := Command{
-					Command: fmt.Sprintf(config.ClobberCommandFormat, task.Module.Location, value),
-				}
-				commands = append(commands, &command)
+func (tb *taskBuilder) applyEnvironment(tasks []*Task) {
+	for _, task := range tasks {
+		for _, command := range task.Commands {
+			env := tb.appConfig.Environment(task.Target)
+			for key, value := range command.Env {
+				env[key] = value
+			}
+			command.Env = env
+			if task.Module == nil {
+				continue
 			}
-			command := Command{
-				Command: fmt.Sprintf(config.BuildCommandFormat, task.Module.Location, value),
+			switch {
+			case command.Dir == "":
+				command.Dir = task.Module.WorkingDirectory()
+			case !filepath.IsAbs(command.Dir):
+				command.Dir = filepath.Join(task.Module.Location, command.Dir)
 			}
-			commands = append(commands, &command)
 		}
 	}
+}
 
-	for key, value := range config.NonClobberableSources {
-		if strings.Contains(task.targets, key) {
-			command := Command{
-				Command: fmt.Sprintf(config.BuildCommandFormat, task.Module.Location, value),
+func (tb *taskBuilder) createBuildCommands(task Task) ([]*Command, error) {
+	templates := tb.moduleTemplates(task)
+	commands := make([]*Command, 0, 5)
+	clobber := strings.Contains(task.targets, config.ClobberSymbol) && !task.Module.NoClobber
+	for _, sourceSet := range tb.appConfig.OrderedSourceSets() {
+		if !strings.Contains(task.targets, sourceSet.Symbol) || task.Module.SourceDisabled(sourceSet) {
+			continue
+		}
+		context := tb.newCommandContext(task)
+		context.Source = sourceSet.Directory
+		if sourceSet.Clobberable && clobber {
+			command, err := renderCommand("clobber", templates.Clobber, context)
+			if err != nil {
+				return nil, err
 			}
-			commands = append(commands, &command)
+			command.Source, command.RequiresRestart = sourceSet.Directory, sourceSet.RequiresRestart
+			commands = append(commands, command)
+		}
+		buildTemplate := templates.Build
+		if sourceSet.Template != "" && task.Module.Templates.Build == "" {
+			buildTemplate = sourceSet.Template
 		}
+		command, err := renderCommand("build", buildTemplate, context)
+		if err != nil {
+			return nil, err
+		}
+		command.Source, command.RequiresRestart = sourceSet.Directory, sourceSet.RequiresRestart
+		commands = append(commands, command)
 	}
-	return commands
+	return commands, nil
 }
 
-func (tb *taskBuilder) createTestCommands(task Task) *Command {
-	testCommand := fmt.Sprintf(config.TestCommandFormat, strings.ReplaceAll(task.Target.String(), "_", "."), task.Module.Location, config.SrcAliases[config.SrcTestSymbol])
-	if task.targets != "" {
-		testCommand = testCommand + fmt.Sprintf(config.SpecificTestCommandFormat, task.targets)
+func (tb *taskBuilder) createTestCommands(task Task) (*Command, error) {
+	context := tb.newCommandContext(task)
+	context.Source = tb.sourceDirectory(config.SrcTestSymbol)
+	context.Target = strings.ReplaceAll(task.Target.String(), "_", ".")
+	context.TestIncludes = task.targets
+	return renderCommand("test", tb.moduleTemplates(task).Test, context)
+}
+
+func (tb *taskBuilder) createNumKeyCommands(task Task) ([]*Command, error) {
+	sourceSet, _ := tb.appConfig.SourceSet(config.SrcSymbol)
+	context := tb.newCommandContext(task)
+	context.Source = sourceSet.Directory
+	templates := tb.moduleTemplates(task)
+	start, err := renderCommand("num_key_start", templates.NumKeyStart, context)
+	if err != nil {
+		return nil, err
+	}
+	build, err := renderCommand("num_key", templates.NumKey, context)
+	if err != nil {
+		return nil, err
+	}
+	build.Source, build.RequiresRestart = sourceSet.Directory, sourceSet.RequiresRestart
+	stop, err := renderCommand("num_key_stop", templates.NumKeyStop, context)
+	if err != nil {
+		return nil, err
+	}
+	stop.Finally = true
+	return []*Command{start, build, stop}, nil
+}
+
+func (tb *taskBuilder) createLifecycleTasks(arguments *config.RunCommand, targets ...config.Target) ([]*Task, error) {
+	tasks := make([]*Task, 0, len(targets))
+	for _, target := range targets {
+		if !arguments.Lifecycle(target) {
+			continue
+		}
+		command, check := tb.appConfig.Commands.OOTB.Lifecycle(target)
+		if command == "" {
+			return nil, fmt.Errorf("%s requested but commands.ootb.%s is not configured", target, target)
+		}
+		task := &Task{
+			Target:   target,
+			Commands: []*Command{{Command: command, Check: check, Shutdown: target == config.Stop}},
+		}
+		if target == config.Restart && arguments.RestartAuto && !arguments.Restart {
+			task.autoRestart = true
+			task.Commands[0].Note = "restart=auto, decided after builds"
+		}
+		tasks = append(tasks, task)
 	}
-	return &Command{Command: testCommand}
+	return tasks, nil
 }
 
-func (tb *taskBuilder) createNumKeyCommand(task Task) *Command {
-	testCommand := fmt.Sprintf(config.NumKeyBuildCommandFormat, task.Module.Location, config.SrcAliases[config.SrcSymbol])
-	return &Command{Command: testCommand}
+func (tb *taskBuilder) sourceDirectory(symbol string) string {
+	sourceSet, _ := tb.appConfig.SourceSet(symbol)
+	return sourceSet.Directory
 }
 
-func (tb *taskBuilder) createCustomCommands(task Task) (*Command, error) {
-	command := tb.appConfig.Commands.Custom[task.targets]
-	if command != "" {
-		return &Command{Command: command}, nil
+func (tb *taskBuilder) createCustomCommands(task Task) ([]*Command, error) {
+	spec := parseCustomSpec(task.targets)
+	command := tb.appConfig.Commands.Custom[spec.name]
+	if !command.Defined() {
+		if tb.appConfig.FailOnError {
+			return nil, fmt.Errorf("command %s not found in custom commands", spec.name)
+		}
+		return []*Command{{Command: task.targets, Status: config.Failed}}, nil
+	}
+	values, err := tb.customValues(spec, command, task.Module)
+	if err != nil {
+		return nil, err
 	}
-	if tb.appConfig.FailOnError {
-		return nil, fmt.Errorf("command %s not found in custom commands", task.targets)
+	commands := make([]*Command, 0, len(command.Steps))
+	for _, step := range command.CommandSteps() {
+		rendered, err := renderCustomCommand(spec.name, step.Command, values)
+		if err != nil {
+			return nil, err
+		}
+		customCommand := &Command{Command: rendered}
+		if step.NoShell || command.NoShell {
+			customCommand = newCommand(rendered)
+		}
+		customCommand.Name = step.Name
+		customCommand.Dir = step.Workdir
+		if customCommand.Dir == "" {
+			customCommand.Dir = command.Workdir
+		}
+		customCommand.Timeout = step.Timeout
+		customCommand.ContinueOnError = step.ContinueOnError
+		customCommand.Finally = step.Finally || command.Finally
+		if task.Module != nil {
+			customCommand.Env = task.Module.Env
+			if customCommand.Timeout == 0 {
+				customCommand.Timeout = task.Module.Timeout
+			}
+		}
+		commands = append(commands, customCommand)
 	}
-	return &Command{Command: task.targets, Status: config.Failed}, nil
+	return commands, nil
 }
@@ -0,0 +1,454 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+func Test_taskBuilder_validateBuildSpec(t *testing.T) {
+	moduleInfo := &module.ModuleInfo{
+		Name:    "ModuleA",
+		Sources: []string{config.SRC, config.SrcWeb},
+	}
+	tests := []struct {
+		name      string
+		appConfig *config.AppConfig
+		targets   string
+		wantErr   bool
+	}{
+		{
+			name:      "Should accept existing sources",
+			appConfig: &config.AppConfig{},
+			targets:   "swc",
+			wantErr:   false,
+		},
+		{
+			name:      "Should reject unknown symbol",
+			appConfig: &config.AppConfig{},
+			targets:   "sx",
+			wantErr:   true,
+		},
+		{
+			name:      "Should reject missing source directory",
+			appConfig: &config.AppConfig{},
+			targets:   "t",
+			wantErr:   true,
+		},
+		{
+			name:      "Should reject clobber of non clobberable sources",
+			appConfig: &config.AppConfig{},
+			targets:   "wc",
+			wantErr:   true,
+		},
+		{
+			name:      "Should only warn when configured",
+			appConfig: &config.AppConfig{SpecValidation: config.SpecValidationWarn},
+			targets:   "tx",
+			wantErr:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &taskBuilder{appConfig: tt.appConfig}
+			err := tb.validateBuildSpec(moduleInfo, tt.targets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBuildSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_taskBuilder_expandTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []string
+		targets string
+		want    string
+	}{
+		{
+			name:    "Should keep explicit targets",
+			sources: []string{config.SRC},
+			targets: "sc",
+			want:    "sc",
+		},
+		{
+			name:    "Should expand all sources in canonical order",
+			sources: []string{config.SrcWeb, config.SRC, config.SrcTest, "src_other"},
+			targets: "*c",
+			want:    "stwc",
+		},
+		{
+			name:    "Should drop clobber when nothing is clobberable",
+			sources: []string{config.SrcWeb},
+			targets: "*c",
+			want:    "w",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &taskBuilder{appConfig: &config.AppConfig{}}
+			got := tb.expandTargets(&module.ModuleInfo{Sources: tt.sources}, tt.targets)
+			if got != tt.want {
+				t.Errorf("expandTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskBuilder_getModuleSetSpec(t *testing.T) {
+	modulesConfig := map[string]*module.ModuleInfo{
+		"ModuleA": {Name: "ModuleA", Order: 0},
+		"ModuleB": {Name: "ModuleB", Order: 1},
+		"ModuleC": {Name: "ModuleC", Order: 2},
+		"ModuleD": {Name: "ModuleD", Order: -1},
+	}
+	appConfig := &config.AppConfig{
+		Aliases: map[string]string{"mb": "ModuleB", "mx": "ModuleX"},
+		Groups: map[string][]string{
+			"mpm_all": {"ModuleD", "ModuleC_w", "mb"},
+			"nested":  {"@mpm_all", "ModuleA_t"},
+			"cycle":   {"@loop"},
+			"loop":    {"@cycle"},
+		},
+	}
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Should resolve single module",
+			spec: "mb_s",
+			want: []string{"ModuleB_s"},
+		},
+		{
+			name: "Should resolve all ordered modules",
+			spec: "@all",
+			want: []string{"ModuleA_*", "ModuleB_*", "ModuleC_*"},
+		},
+		{
+			name: "Should resolve range with targets",
+			spec: "@range:mb..ModuleC_sc",
+			want: []string{"ModuleB_sc", "ModuleC_sc"},
+		},
+		{
+			name: "Should resolve group in build order with member targets",
+			spec: "@mpm_all",
+			want: []string{"ModuleB_*", "ModuleC_w", "ModuleD_*"},
+		},
+		{
+			name: "Should apply group targets to every member",
+			spec: "@mpm_all_s",
+			want: []string{"ModuleB_s", "ModuleC_s", "ModuleD_s"},
+		},
+		{
+			name: "Should resolve nested groups",
+			spec: "@nested",
+			want: []string{"ModuleA_t", "ModuleB_*", "ModuleC_w", "ModuleD_*"},
+		},
+		{
+			name:    "Should reject group cycles",
+			spec:    "@cycle",
+			wantErr: true,
+		},
+		{
+			name:    "Should reject reversed range",
+			spec:    "@range:ModuleC..ModuleA",
+			wantErr: true,
+		},
+		{
+			name:    "Should reject range with module outside build order",
+			spec:    "@range:ModuleA..ModuleD",
+			wantErr: true,
+		},
+		{
+			name:    "Should reject alias of module missing from registry",
+			spec:    "mx_sw",
+			wantErr: true,
+		},
+		{
+			name:    "Should reject unknown set",
+			spec:    "@unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &taskBuilder{
+				appConfig:     appConfig,
+				modulesConfig: modulesConfig,
+			}
+			got, err := tb.getModuleSetSpec(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getModuleSetSpec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			specs := make([]string, 0, len(got))
+			for _, spec := range got {
+				specs = append(specs, spec.module.Name+"_"+spec.targets)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(specs, tt.want) {
+				t.Errorf("getModuleSetSpec() = %v, want %v", specs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskBuilder_createTestCommands(t *testing.T) {
+	moduleInfo := &module.ModuleInfo{Name: "ModuleA", Location: "/opt/ModuleA"}
+	tests := []struct {
+		name      string
+		appConfig *config.AppConfig
+		task      Task
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "Should render default template",
+			appConfig: &config.AppConfig{},
+			task:      Task{Target: config.TestUnit, Module: moduleInfo},
+			want:      "ant test.unit -f /opt/ModuleA/src_test/build.xml",
+		},
+		{
+			name:      "Should render default template with test includes",
+			appConfig: &config.AppConfig{},
+			task:      Task{Target: config.TestIntegration, Module: moduleInfo, targets: "MyTest"},
+			want:      "ant test.integration -f /opt/ModuleA/src_test/build.xml '-Dtest.includes=**/MyTest'",
+		},
+		{
+			name: "Should render configured template",
+			appConfig: &config.AppConfig{Root: "/opt", Templates: config.Templates{
+				Test: "{{.Root}}/ant.sh {{.Target}} {{.Module.Name}}",
+			}},
+			task: Task{Target: config.TestUnit, Module: moduleInfo},
+			want: "/opt/ant.sh test.unit ModuleA",
+		},
+		{
+			name: "Should fail on unknown template variable",
+			appConfig: &config.AppConfig{Templates: config.Templates{
+				Test: "ant {{.Unknown}}",
+			}},
+			task:    Task{Target: config.TestUnit, Module: moduleInfo},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &taskBuilder{appConfig: tt.appConfig}
+			got, err := tb.createTestCommands(tt.task)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("createTestCommands() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Command != tt.want {
+				t.Errorf("createTestCommands() = %v, want %v", got.Command, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskBuilder_createBuildCommands(t *testing.T) {
+	moduleInfo := &module.ModuleInfo{Name: "ModuleA", Location: "/opt/ModuleA"}
+	tests := []struct {
+		name      string
+		appConfig *config.AppConfig
+		targets   string
+		want      []string
+	}{
+		{
+			name:      "Should create commands in source set order",
+			appConfig: &config.AppConfig{},
+			targets:   "wsc",
+			want: []string{
+				"ant clobber -f /opt/ModuleA/src/build.xml",
+				"ant -f /opt/ModuleA/src/build.xml",
+				"ant -f /opt/ModuleA/src_web/build.xml",
+			},
+		},
+		{
+			name: "Should use configured source sets",
+			appConfig: &config.AppConfig{SourceSets: map[string]config.SourceSet{
+				"g": {Directory: "src_gen", Order: 5, Template: "ant generate -f {{.Module.Location}}/{{.Source}}/build.xml"},
+				"w": {Directory: "src_web", Clobberable: true, Order: 30},
+			}},
+			targets: "swgc",
+			want: []string{
+				"ant generate -f /opt/ModuleA/src_gen/build.xml",
+				"ant clobber -f /opt/ModuleA/src/build.xml",
+				"ant -f /opt/ModuleA/src/build.xml",
+				"ant clobber -f /opt/ModuleA/src_web/build.xml",
+				"ant -f /opt/ModuleA/src_web/build.xml",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &taskBuilder{appConfig: tt.appConfig}
+			commands, err := tb.createBuildCommands(Task{Target: config.Build, Module: moduleInfo, targets: tt.targets})
+			if err != nil {
+				t.Errorf("createBuildCommands() error = %v", err)
+				return
+			}
+			got := make([]string, 0, len(commands))
+			for _, command := range commands {
+				got = append(got, command.Command)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("createBuildCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskBuilder_BuildTasks_lifecycle(t *testing.T) {
+	appConfig := &config.AppConfig{Commands: config.Commands{OOTB: config.OOTBCommands{
+		Start:      "windchill start",
+		Stop:       "windchill stop",
+		Restart:    "windchill stop && windchill start",
+		ClearCache: "rm -rf cache",
+	}, Custom: map[string]config.CustomCommand{"deploy": {Command: "deploy"}}}}
+	moduleInfo := &module.ModuleInfo{Name: "ModuleA", Location: "/opt/ModuleA", Sources: config.TestSources}
+	tests := []struct {
+		name      string
+		arguments *config.RunCommand
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "Should order lifecycle around builds and tests",
+			arguments: &config.RunCommand{Start: true, Stop: true, ClearCache: true, TestIntegration: []string{"ModuleA"}, Build: []string{"ModuleA_s"}},
+			want:      []string{"stop", "clear_cache", "build ModuleA", "start", "test_integration ModuleA"},
+		},
+		{
+			name:      "Should run custom commands before restart",
+			arguments: &config.RunCommand{Restart: true, Custom: []string{"deploy"}, Build: []string{"ModuleA_s"}},
+			want:      []string{"build ModuleA", "custom deploy", "restart"},
+		},
+		{
+			name:      "Should test before custom commands without server lifecycle",
+			arguments: &config.RunCommand{Custom: []string{"deploy"}, TestUnit: []string{"ModuleA"}, Build: []string{"ModuleA_s"}},
+			want:      []string{"build ModuleA", "test_unit ModuleA", "custom deploy"},
+		},
+		{
+			name:      "Should test after restart",
+			arguments: &config.RunCommand{RestartAuto: true, Custom: []string{"deploy"}, TestUnit: []string{"ModuleA"}, Build: []string{"ModuleA_s"}},
+			want:      []string{"build ModuleA", "custom deploy", "restart", "test_unit ModuleA"},
+		},
+		{
+			name:      "Should fail on unconfigured command",
+			arguments: &config.RunCommand{Status: true},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &taskBuilder{appConfig: appConfig, modulesConfig: map[string]*module.ModuleInfo{"ModuleA": moduleInfo}}
+			tasks, err := tb.BuildTasks(tt.arguments)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := make([]string, 0, len(tasks))
+			for _, task := range tasks {
+				got = append(got, task.Describe())
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildTasks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskBuilder_applyEnvironment(t *testing.T) {
+	appConfig := &config.AppConfig{
+		Env:       map[string]string{"A": "global", "B": "global"},
+		TargetEnv: map[string]map[string]string{"build": {"B": "build"}},
+	}
+	moduleInfo := &module.ModuleInfo{Name: "ModuleA", Location: "/opt/ModuleA"}
+	build := &Command{Command: "ant", Env: map[string]string{"C": "module"}}
+	step := &Command{Command: "regen", Dir: "src/bundles"}
+	restart := &Command{Command: "restart"}
+	tasks := []*Task{
+		{Target: config.Build, Module: moduleInfo, Commands: []*Command{build}},
+		{Target: config.Custom, Module: moduleInfo, Commands: []*Command{step}},
+		{Target: config.Restart, Commands: []*Command{restart}},
+	}
+	tb := &taskBuilder{appConfig: appConfig}
+	tb.applyEnvironment(tasks)
+
+	tests := []struct {
+		name    string
+		command *Command
+		wantEnv map[string]string
+		wantDir string
+	}{
+		{name: "Should layer target and module env", command: build, wantEnv: map[string]string{"A": "global", "B": "build", "C": "module"}, wantDir: "/opt/ModuleA"},
+		{name: "Should resolve relative workdir", command: step, wantEnv: map[string]string{"A": "global", "B": "global"}, wantDir: "/opt/ModuleA/src/bundles"},
+		{name: "Should keep cwd without module", command: restart, wantEnv: map[string]string{"A": "global", "B": "global"}, wantDir: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.command.Env, tt.wantEnv) || tt.command.Dir != tt.wantDir {
+				t.Errorf("applyEnvironment() = %v %q, want %v %q", tt.command.Env, tt.command.Dir, tt.wantEnv, tt.wantDir)
+			}
+		})
+	}
+}
+
+func Test_taskBuilder_createNumKeyCommands(t *testing.T) {
+	moduleInfo := &module.ModuleInfo{Name: "ModuleA", Location: "/opt/ModuleA", Sources: config.TestSources}
+	tb := &taskBuilder{appConfig: &config.AppConfig{}}
+	commands, err := tb.createNumKeyCommands(Task{Target: config.NumKey, Module: moduleInfo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := commands[1]
+	if build.Source != config.SRC || !build.RequiresRestart {
+		t.Errorf("createNumKeyCommands() build = %q %v, want %q true", build.Source, build.RequiresRestart, config.SRC)
+	}
+	previous := []*Task{{Target: config.NumKey, Module: moduleInfo, Commands: []*Command{{Status: config.Completed, Source: build.Source, RequiresRestart: build.RequiresRestart}}}}
+	restart := &Task{Target: config.Restart, Commands: []*Command{{Command: "restart"}}, autoRestart: true}
+	decideRestart(restart, previous)
+	if restart.Commands[0].Status != config.Prepared {
+		t.Errorf("decideRestart() after num_key = %s %q, want %s", restart.Commands[0].Status, restart.Commands[0].Note, config.Prepared)
+	}
+}
+
+func Test_taskBuilder_BuildTasks_moduleSets(t *testing.T) {
+	modulesConfig := map[string]*module.ModuleInfo{
+		"ModuleA": {Name: "ModuleA", Order: 0, Location: "/opt/ModuleA", Sources: []string{config.SRC, config.SrcWeb}},
+		"ModuleB": {Name: "ModuleB", Order: 1, Location: "/opt/ModuleB", Sources: []string{config.SRC}},
+		"ModuleC": {Name: "ModuleC", Order: 2, Location: "/opt/ModuleC", Sources: []string{config.SrcWeb}},
+	}
+	appConfig := &config.AppConfig{Groups: map[string][]string{"web": {"ModuleA", "ModuleB"}}}
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Should build requested sources of every set member that has them", spec: "@all_w", want: []string{"ModuleA w", "ModuleC w"}},
+		{name: "Should intersect range targets", spec: "@range:ModuleA..ModuleB_sc", want: []string{"ModuleA sc", "ModuleB sc"}},
+		{name: "Should intersect group targets", spec: "@web_w", want: []string{"ModuleA w"}},
+		{name: "Should keep unknown symbols strict for sets", spec: "@all_x", wantErr: true},
+		{name: "Should keep single module specs strict", spec: "ModuleB_w", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &taskBuilder{appConfig: appConfig, modulesConfig: modulesConfig}
+			tasks, err := tb.BuildTasks(&config.RunCommand{Build: []string{tt.spec}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := make([]string, 0, len(tasks))
+			for _, task := range tasks {
+				got = append(got, task.Module.Name+" "+task.targets)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildTasks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
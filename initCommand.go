@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"wnc_builder/config"
+	"wnc_builder/module"
+
+	"gopkg.in/yaml.v3"
+)
+
+const buildOrderFile = "compile.includes"
+const moduleRegistryFile = "moduleRegistry.xml"
+const detectionDepth = 5
+
+type initConfig struct {
+	Profile        string            `yaml:"profile"`
+	Root           string            `yaml:"root"`
+	FailOnError    bool              `yaml:"fail_on_error"`
+	SpecValidation string            `yaml:"spec_validation"`
+	Commands       initCommands      `yaml:"commands"`
+	Input          config.Input      `yaml:"input"`
+	Aliases        map[string]string `yaml:"aliases"`
+}
+
+type initCommands struct {
+	OOTB   config.OOTBCommands `yaml:"ootb"`
+	Custom map[string]string   `yaml:"custom"`
+}
+
+type prompter struct {
+	reader *bufio.Reader
+	yes    bool
+}
+
+func runInitCommand(command *config.InitCommand) error {
+	return runInit(command, os.Stdin)
+}
+
+func runInit(command *config.InitCommand, input io.Reader) error {
+	output, err := initOutputPath(command)
+	if err != nil {
+		return err
+	}
+	err = checkInitOutput(output, command.Force)
+	if err != nil {
+		return err
+	}
+
+	root, err := initRoot(command)
+	if err != nil {
+		return err
+	}
+	ask := prompter{reader: bufio.NewReader(input), yes: command.Yes}
+	buildOrder, moduleRegistry := detectInputs(root)
+	buildOrder, err = ask.path(buildOrderFile, buildOrder)
+	if err != nil {
+		return err
+	}
+	moduleRegistry, err = ask.path(moduleRegistryFile, moduleRegistry)
+	if err != nil {
+		return err
+	}
+
+	names, err := module.ReadRegistryNames(moduleRegistry)
+	if err != nil {
+		return err
+	}
+	generated := initConfig{
+		Profile:        "prod",
+		Root:           root,
+		SpecValidation: config.SpecValidationError,
+		Commands: initCommands{
+			OOTB: config.OOTBCommands{
+				Start:   "windchill start",
+				Stop:    "windchill stop",
+				Restart: "windchill stop && windchill start",
+				Status:  "windchill status",
+			},
+			Custom: map[string]string{},
+		},
+		Input:   config.Input{BuildOrder: buildOrder, ModuleRegistry: moduleRegistry},
+		Aliases: module.SuggestAliases(names, nil),
+	}
+	if !ask.confirm(fmt.Sprintf("Write configuration with %d aliases to %s?", len(generated.Aliases), output)) {
+		return errors.New("configuration was not written")
+	}
+	return writeInitConfig(output, generated)
+}
+
+func initOutputPath(command *config.InitCommand) (string, error) {
+	if command.Output != "" {
+		return command.Output, nil
+	}
+	return config.UserConfigPath()
+}
+
+func checkInitOutput(output string, force bool) error {
+	if _, err := os.Stat(output); err == nil && !force {
+		return fmt.Errorf("configuration file %q already exists, use --force to overwrite it", output)
+	}
+	return nil
+}
+
+func initRoot(command *config.InitCommand) (string, error) {
+	root := command.Root
+	if root == "" {
+		root = os.Getenv("WT_HOME")
+	}
+	if root == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("working directory is not available. %w", err)
+		}
+		root = cwd
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve root %q. %w", root, err)
+	}
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("root %q is not a directory", root)
+	}
+	return root, nil
+}
+
+func detectInputs(root string) (string, string) {
+	var buildOrder, moduleRegistry string
+	baseDepth := strings.Count(root, string(os.PathSeparator))
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if path != root && (strings.HasPrefix(entry.Name(), ".") || strings.Count(path, string(os.PathSeparator))-baseDepth >= detectionDepth) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case entry.Name() == buildOrderFile && buildOrder == "":
+			buildOrder = path
+		case entry.Name() == moduleRegistryFile && moduleRegistry == "":
+			moduleRegistry = path
+		}
+		if buildOrder != "" && moduleRegistry != "" {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return buildOrder, moduleRegistry
+}
+
+func writeInitConfig(output string, generated initConfig) error {
+	err := os.MkdirAll(filepath.Dir(output), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("could not create configuration directory. %w", err)
+	}
+	configFile, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("could not create configuration file. %w", err)
+	}
+	defer configFile.Close()
+	encoder := yaml.NewEncoder(configFile)
+	encoder.SetIndent(2)
+	err = encoder.Encode(generated)
+	if err != nil {
+		return fmt.Errorf("could not write configuration to config file. %w", err)
+	}
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("could not write configuration to config file. %w", err)
+	}
+	fmt.Printf("%s OK %s configuration written to %s\n", config.OkColor, config.NoColor, output)
+	return nil
+}
+
+func (p prompter) path(name string, detected string) (string, error) {
+	if detected != "" && (p.yes || p.confirm(fmt.Sprintf("Use detected %s %s?", name, detected))) {
+		return detected, nil
+	}
+	if p.yes {
+		return "", fmt.Errorf("%s was not found under the Windchill root, pass --root or create the file manually", name)
+	}
+	answer, err := p.line(fmt.Sprintf("Path to %s: ", name))
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(answer); err != nil {
+		return "", fmt.Errorf("%s %q is not available. %w", name, answer, err)
+	}
+	return filepath.Abs(answer)
+}
+
+func (p prompter) confirm(question string) bool {
+	if p.yes {
+		return true
+	}
+	answer, err := p.line(question + " [Y/n] ")
+	if err != nil {
+		return false
+	}
+	return answer == "" || strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes")
+}
+
+func (p prompter) line(question string) (string, error) {
+	fmt.Print(question)
+	answer, err := p.reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && answer != "") {
+		return "", fmt.Errorf("could not read answer. %w", err)
+	}
+	return strings.TrimSpace(answer), nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wnc_builder/config"
+)
+
+func writeInitFixture(t *testing.T, path string, content string) string {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err == nil {
+		err = os.WriteFile(path, []byte(content), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_detectInputs(t *testing.T) {
+	registry, err := os.ReadFile("testFixtures/moduleRegistry.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		files     []string
+		wantOrder string
+		wantReg   string
+	}{
+		{
+			name:      "Should detect inputs in nested directories",
+			files:     []string{"codebase/" + buildOrderFile, "codebase/wt/" + moduleRegistryFile},
+			wantOrder: "codebase/" + buildOrderFile,
+			wantReg:   "codebase/wt/" + moduleRegistryFile,
+		},
+		{
+			name:  "Should ignore hidden directories",
+			files: []string{".git/" + buildOrderFile, ".cache/" + moduleRegistryFile},
+		},
+		{
+			name:      "Should ignore files below detection depth",
+			files:     []string{buildOrderFile, "a/b/c/d/e/" + moduleRegistryFile},
+			wantOrder: buildOrderFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, file := range tt.files {
+				writeInitFixture(t, filepath.Join(root, file), string(registry))
+			}
+			order, reg := detectInputs(root)
+			if rel(root, order) != tt.wantOrder || rel(root, reg) != tt.wantReg {
+				t.Errorf("detectInputs() = %q %q, want %q %q", rel(root, order), rel(root, reg), tt.wantOrder, tt.wantReg)
+			}
+		})
+	}
+}
+
+func rel(root string, path string) string {
+	if path == "" {
+		return ""
+	}
+	relative, _ := filepath.Rel(root, path)
+	return filepath.ToSlash(relative)
+}
+
+func Test_runInit(t *testing.T) {
+	registry, err := os.ReadFile("testFixtures/moduleRegistry.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		existing     bool
+		force        bool
+		yes          bool
+		withRegistry bool
+		input        string
+		wantErr      string
+		wantWritten  bool
+	}{
+		{name: "Should refuse to overwrite without force", existing: true, yes: true, withRegistry: true, wantErr: "already exists"},
+		{name: "Should overwrite with force", existing: true, force: true, yes: true, withRegistry: true, wantWritten: true},
+		{name: "Should write detected inputs with yes", yes: true, withRegistry: true, wantWritten: true},
+		{name: "Should not ask for missing inputs with yes", yes: true, wantErr: "was not found"},
+		{name: "Should not write when not confirmed", withRegistry: true, input: "y\ny\nn\n", wantErr: "not written"},
+		{name: "Should write when confirmed", withRegistry: true, input: "\n\n\n", wantWritten: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			output := filepath.Join(t.TempDir(), "config.yml")
+			writeInitFixture(t, filepath.Join(root, buildOrderFile), "ModuleA\n")
+			if tt.withRegistry {
+				writeInitFixture(t, filepath.Join(root, "wt", moduleRegistryFile), string(registry))
+			}
+			if tt.existing {
+				writeInitFixture(t, output, "existing: true\n")
+			}
+			command := &config.InitCommand{Root: root, Output: output, Yes: tt.yes, Force: tt.force}
+
+			stdout := os.Stdout
+			os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			err := runInit(command, strings.NewReader(tt.input))
+			os.Stdout = stdout
+
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("runInit() error = %v, want %q", err, tt.wantErr)
+			}
+			content, _ := os.ReadFile(output)
+			written := strings.Contains(string(content), "module_registry: "+filepath.Join(root, "wt", moduleRegistryFile))
+			if written != tt.wantWritten {
+				t.Errorf("runInit() written = %v, want %v:\n%s", written, tt.wantWritten, content)
+			}
+		})
+	}
+}
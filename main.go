@@ -9,31 +9,65 @@ import (
 )
 
 func main() {
-	appConfig, err := config.CreateAppConfig()
-	if err != nil {
-		fmt.Println(err.Error())
+	cmdArgs := config.ParseCmdArgs()
+	switch {
+	case cmdArgs.Init != nil:
+		exitOnError(runInitCommand(cmdArgs.Init))
+		return
+	case cmdArgs.Completion != nil:
+		exitOnError(runCompletionCommand(cmdArgs.Completion))
+		return
+	case cmdArgs.Complete != nil:
+		runCompleteCommand(cmdArgs)
+		return
+	}
+
+	appConfig, err := config.CreateAppConfig(loadOptions(cmdArgs))
+	exitOnError(err)
+
+	switch {
+	case cmdArgs.ConfigCommand != nil:
+		exitOnError(runConfigCommand(appConfig, cmdArgs))
+		return
+	case cmdArgs.CustomCommands != nil:
+		exitOnError(runCustomCommand(appConfig, cmdArgs))
+		return
+	}
+
+	if !appConfig.Configured() {
+		fmt.Println("no configuration file found, create one with `wcb init --root <windchill root>`")
 		os.Exit(1)
 	}
-	cmdArgs := config.ParseCmdArgs()
 
 	moduleInfos, err := module.CalculateModuleInfo(appConfig)
-	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+	exitOnError(err)
+
+	switch {
+	case cmdArgs.Modules != nil:
+		exitOnError(runModulesCommand(appConfig, moduleInfos, cmdArgs.Modules))
+		return
+	case cmdArgs.Alias != nil:
+		exitOnError(runAliasCommand(appConfig, moduleInfos, cmdArgs))
+		return
 	}
 
 	taskBuilder := executor.NewTaskBuilder(appConfig, moduleInfos)
-	tasks, err := taskBuilder.BuildTasks(cmdArgs)
-	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
-	}
+	tasks, err := taskBuilder.BuildTasks(cmdArgs.Run)
+	exitOnError(err)
 
 	taskExecutor := executor.NewTaskExecutor(appConfig, moduleInfos)
-	if !cmdArgs.Dry {
+	if !cmdArgs.Run.Dry {
 		err = taskExecutor.RunTasks(tasks)
 	}
 	taskExecutor.PrintSummary(tasks)
+	exitOnError(err)
+}
+
+func loadOptions(cmdArgs *config.ProgramArguments) config.LoadOptions {
+	return config.LoadOptions{ConfigPath: cmdArgs.Config, Profile: cmdArgs.Profile}
+}
+
+func exitOnError(err error) {
 	if err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
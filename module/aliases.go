@@ -0,0 +1,75 @@
+package module
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"wnc_builder/config"
+)
+
+const minAliasLength = 2
+const shortNameLength = 3
+
+func ReverseAliases(cfg *config.AppConfig) map[string][]string {
+	result := make(map[string][]string)
+	for alias, name := range cfg.Aliases {
+		result[name] = append(result[name], alias)
+	}
+	for name := range result {
+		slices.Sort(result[name])
+	}
+	return result
+}
+
+func SuggestAliases(names []string, taken map[string]string) map[string]string {
+	used := make(map[string]bool, len(taken))
+	for alias := range taken {
+		used[strings.ToLower(alias)] = true
+	}
+	sorted := slices.Clone(names)
+	slices.Sort(sorted)
+
+	result := make(map[string]string, len(sorted))
+	for _, name := range sorted {
+		for _, candidate := range aliasCandidates(name) {
+			if !used[candidate] {
+				used[candidate] = true
+				result[candidate] = name
+				break
+			}
+		}
+	}
+	return result
+}
+
+func aliasCandidates(name string) []string {
+	initials, lastInitial := camelInitials(name)
+	base := initials
+	if len(base) < minAliasLength {
+		base = strings.ToLower(name[:min(len(name), shortNameLength)])
+		lastInitial = len(base) - 1
+	}
+	candidates := []string{base}
+	rest := strings.ToLower(name[min(lastInitial+1, len(name)):])
+	for i := 1; i <= len(rest); i++ {
+		candidates = append(candidates, base+rest[:i])
+	}
+	for i := 2; i < 100; i++ {
+		candidates = append(candidates, base+strconv.Itoa(i))
+	}
+	return candidates
+}
+
+func camelInitials(name string) (string, int) {
+	initials := strings.Builder{}
+	lastInitial := 0
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) || unicode.IsDigit(r) || (i == 0 && unicode.IsLetter(r)) {
+			initials.WriteRune(unicode.ToLower(r))
+			lastInitial = i
+		}
+	}
+	return initials.String(), lastInitial
+}
@@ -0,0 +1,68 @@
+package module
+
+import (
+	"slices"
+	"strings"
+	"wnc_builder/config"
+)
+
+func ModuleCandidates(cfg *config.AppConfig, infos map[string]*ModuleInfo, prefix string) []string {
+	idx := strings.Index(prefix, "_")
+	if idx < 0 {
+		return withPrefix(moduleIds(cfg, infos), prefix)
+	}
+
+	id, targets := prefix[:idx], prefix[idx+1:]
+	symbols := make([]string, 0)
+	if targets == "" {
+		symbols = append(symbols, config.AllSourcesSymbol)
+	}
+	moduleInfo := findModule(cfg, infos, id)
+	if moduleInfo == nil && !strings.HasPrefix(id, config.ModuleSetPrefix) {
+		return nil
+	}
+	if moduleInfo == nil || !moduleInfo.NoClobber {
+		symbols = append(symbols, config.ClobberSymbol)
+	}
+	for _, sourceSet := range cfg.OrderedSourceSets() {
+		if moduleInfo != nil && (!slices.Contains(moduleInfo.Sources, sourceSet.Directory) || moduleInfo.SourceDisabled(sourceSet)) {
+			continue
+		}
+		symbols = append(symbols, sourceSet.Symbol)
+	}
+
+	candidates := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if !strings.Contains(targets, symbol) && !strings.Contains(targets, config.AllSourcesSymbol) {
+			candidates = append(candidates, prefix+symbol)
+		}
+	}
+	return candidates
+}
+
+func moduleIds(cfg *config.AppConfig, infos map[string]*ModuleInfo) []string {
+	ids := config.SortedKeys(cfg.Aliases)
+	ids = append(ids, config.SortedKeys(infos)...)
+	ids = append(ids, config.ModuleSetPrefix+config.AllModulesSet)
+	for _, group := range config.SortedKeys(cfg.Groups) {
+		ids = append(ids, config.ModuleSetPrefix+group)
+	}
+	return ids
+}
+
+func findModule(cfg *config.AppConfig, infos map[string]*ModuleInfo, id string) *ModuleInfo {
+	if name, ok := cfg.Aliases[id]; ok {
+		return infos[name]
+	}
+	return infos[id]
+}
+
+func withPrefix(values []string, prefix string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -5,16 +5,58 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 	"wnc_builder/config"
 )
 
 type ModuleInfo struct {
-	Name     string
-	Location string
-	Order    int
-	Sources  []string
+	Name            string
+	Location        string
+	Order           int
+	Sources         []string
+	BuildFile       string
+	Properties      map[string]string
+	Env             map[string]string
+	Timeout         time.Duration
+	Workdir         string
+	DisabledSources []string
+	NoClobber       bool
+	Templates       config.Templates
+	Aliases         []string
+}
+
+func (m *ModuleInfo) InBuildOrder() bool {
+	return m.Order >= 0
+}
+
+func (m *ModuleInfo) DisplayName() string {
+	if len(m.Aliases) == 0 {
+		return m.Name
+	}
+	return fmt.Sprintf("%s (%s)", m.Name, strings.Join(m.Aliases, ", "))
+}
+
+func (m *ModuleInfo) Exists() bool {
+	info, err := os.Stat(m.Location)
+	return err == nil && info.IsDir()
+}
+
+func (m *ModuleInfo) WorkingDirectory() string {
+	switch {
+	case m.Workdir == "":
+		return m.Location
+	case filepath.IsAbs(m.Workdir):
+		return m.Workdir
+	}
+	return filepath.Join(m.Location, m.Workdir)
+}
+
+func (m *ModuleInfo) SourceDisabled(sourceSet config.SourceSet) bool {
+	return slices.Contains(m.DisabledSources, sourceSet.Symbol) || slices.Contains(m.DisabledSources, sourceSet.Directory)
 }
 
 type moduleXML struct {
@@ -35,7 +77,9 @@ func CalculateModuleInfo(cfg *config.AppConfig) (map[string]*ModuleInfo, error)
 		return nil, err
 	}
 	sourceCalculator := buildSourceCalculator(cfg)
-	calculators := []func(info *ModuleInfo) error{orderCalculator, sourceCalculator}
+	overrideCalculator := buildOverrideCalculator(cfg)
+	aliasCalculator := buildAliasCalculator(cfg)
+	calculators := []func(info *ModuleInfo) error{orderCalculator, sourceCalculator, overrideCalculator, aliasCalculator}
 	infos, err := buildModuleInfos(cfg, calculators)
 	if err != nil {
 		return nil, err
@@ -49,7 +93,11 @@ func buildOrderCalculator(cfg *config.AppConfig) (func(info *ModuleInfo) error,
 		return nil, err
 	}
 	return func(info *ModuleInfo) error {
-		info.Order = result[info.Name]
+		order, ok := result[info.Name]
+		if !ok {
+			order = -1
+		}
+		info.Order = order
 		return nil
 	}, nil
 }
@@ -80,10 +128,6 @@ func buildOrderMap(cfg *config.AppConfig) (map[string]int, error) {
 
 func buildSourceCalculator(cfg *config.AppConfig) func(info *ModuleInfo) error {
 	return func(info *ModuleInfo) error {
-		if cfg.Profile == "test" {
-			info.Sources = config.TestSources
-			return nil
-		}
 		entries, err := os.ReadDir(info.Location)
 		if err != nil {
 			return nil
@@ -99,20 +143,74 @@ func buildSourceCalculator(cfg *config.AppConfig) func(info *ModuleInfo) error {
 	}
 }
 
-func buildModuleInfos(cfg *config.AppConfig, calculators []func(info *ModuleInfo) error) (map[string]*ModuleInfo, error) {
-	moduleRegistryPath := cfg.Input.ModuleRegistry
+func buildOverrideCalculator(cfg *config.AppConfig) func(info *ModuleInfo) error {
+	return func(info *ModuleInfo) error {
+		info.BuildFile = config.DefaultBuildFile
+		override, ok := cfg.ModuleOverride(info.Name)
+		if !ok {
+			return nil
+		}
+		if override.BuildFile != "" {
+			info.BuildFile = override.BuildFile
+		}
+		info.Properties = override.Properties
+		info.Env = override.Env
+		info.Timeout = override.Timeout
+		info.Workdir = override.Workdir
+		info.DisabledSources = override.DisabledSources
+		info.NoClobber = override.NoClobber
+		info.Templates = override.Templates
+		return nil
+	}
+}
+
+func buildAliasCalculator(cfg *config.AppConfig) func(info *ModuleInfo) error {
+	aliases := ReverseAliases(cfg)
+	return func(info *ModuleInfo) error {
+		info.Aliases = aliases[info.Name]
+		return nil
+	}
+}
+
+func readModuleRegistry(moduleRegistryPath string) (modules, error) {
 	fileByteContent, _ := os.ReadFile(moduleRegistryPath)
 
 	modulesFromXml := modules{}
 	err := xml.Unmarshal(fileByteContent, &modulesFromXml)
 	if err != nil {
-		return nil, fmt.Errorf("module info could not be unmarshalled. %w", err)
+		return modules{}, fmt.Errorf("module info could not be unmarshalled. %w", err)
+	}
+	return modulesFromXml, nil
+}
+
+func ReadRegistryNames(moduleRegistryPath string) ([]string, error) {
+	modulesFromXml, err := readModuleRegistry(moduleRegistryPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(modulesFromXml.Modules))
+	for _, xmlModule := range modulesFromXml.Modules {
+		names = append(names, strings.Split(xmlModule.Name, "/")[1])
+	}
+	return names, nil
+}
+
+func buildModuleInfos(cfg *config.AppConfig, calculators []func(info *ModuleInfo) error) (map[string]*ModuleInfo, error) {
+	modulesFromXml, err := readModuleRegistry(cfg.Input.ModuleRegistry)
+	if err != nil {
+		return nil, err
 	}
 
 	result := make(map[string]*ModuleInfo, len(modulesFromXml.Modules))
 	for _, xmlModule := range modulesFromXml.Modules {
 		name := strings.Split(xmlModule.Name, "/")[1]
 		absLocation := strings.Join([]string{cfg.Root, xmlModule.Location}, "/")
+		if !filepath.IsAbs(absLocation) {
+			absLocation, err = filepath.Abs(absLocation)
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve location of module %s. %w", name, err)
+			}
+		}
 		module := ModuleInfo{
 			Name:     name,
 			Location: absLocation,
@@ -2,6 +2,7 @@ package module
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -13,6 +14,7 @@ func buildModulePath(part string) string {
 }
 
 func Test_buildModuleInfos(t *testing.T) {
+	cwd, _ := os.Getwd()
 	type args struct {
 		cfg         *config.AppConfig
 		calculators []func(info *ModuleInfo) error
@@ -80,6 +82,27 @@ func Test_buildModuleInfos(t *testing.T) {
 			}},
 			wantErr: false,
 		},
+		{
+			name: "Should resolve relative root against working directory",
+			args: args{cfg: &config.AppConfig{
+				Root: "opt",
+				Input: config.Input{
+					ModuleRegistry: "../testFixtures/moduleRegistry.xml",
+				},
+			}, calculators: []func(info *ModuleInfo) error{},
+			},
+			want: map[string]*ModuleInfo{"ModuleA": {
+				Name:     "ModuleA",
+				Location: filepath.Join(cwd, buildModulePath("ModuleA")),
+			}, "ModuleB": {
+				Name:     "ModuleB",
+				Location: filepath.Join(cwd, buildModulePath("ModuleB")),
+			}, "ModuleC": {
+				Name:     "ModuleC",
+				Location: filepath.Join(cwd, buildModulePath("ModuleC")),
+			}},
+			wantErr: false,
+		},
 		{
 			name: "Should not parse when incorrect file specified",
 			args: args{cfg: &config.AppConfig{
@@ -167,15 +190,15 @@ func Test_buildSourceCalculator(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "Should create expected sources for test profile",
+			name: "Should detect sources regardless of profile name",
 			args: args{
 				cfg: &config.AppConfig{Profile: "test"},
 			},
 			prep: preparation{
-				root:     "",
-				children: nil,
+				root:     ".",
+				children: []string{"src", "src_web"},
 			},
-			want:    config.TestSources,
+			want:    []string{"src", "src_web"},
 			wantErr: false,
 		},
 		{
@@ -193,14 +216,11 @@ func Test_buildSourceCalculator(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			toRemove := make([]string, 0, len(tt.prep.children)+1)
 			rootPath := tt.prep.root + string(os.PathSeparator) + "root"
-			toRemove = append(toRemove, rootPath)
 			err := os.Mkdir(rootPath, os.ModePerm)
+			defer os.RemoveAll(rootPath)
 			for _, child := range tt.prep.children {
-				path := rootPath + string(os.PathSeparator) + child
-				toRemove = append(toRemove, path)
-				err = os.Mkdir(path, os.ModePerm)
+				err = os.Mkdir(rootPath+string(os.PathSeparator)+child, os.ModePerm)
 			}
 
 			calculator := buildSourceCalculator(tt.args.cfg)
@@ -213,11 +233,136 @@ func Test_buildSourceCalculator(t *testing.T) {
 			if !reflect.DeepEqual(moduleInfo.Sources, tt.want) {
 				t.Errorf("buildSourceCalculator() = %v, want %v", moduleInfo.Sources, tt.want)
 			}
-			for _, path := range toRemove {
-				err := os.Remove(path)
-				if err != nil {
-					return
-				}
+		})
+	}
+}
+
+func Test_buildOverrideCalculator(t *testing.T) {
+	cfg := &config.AppConfig{
+		Aliases: map[string]string{"mb": "ModuleB"},
+		Modules: map[string]config.ModuleOverride{
+			"ModuleA": {BuildFile: "custom.xml", NoClobber: true},
+			"mb":      {Properties: map[string]string{"a": "b"}, DisabledSources: []string{"w"}},
+		},
+	}
+	tests := []struct {
+		name string
+		info ModuleInfo
+		want ModuleInfo
+	}{
+		{
+			name: "Should apply override by module name",
+			info: ModuleInfo{Name: "ModuleA"},
+			want: ModuleInfo{Name: "ModuleA", BuildFile: "custom.xml", NoClobber: true},
+		},
+		{
+			name: "Should apply override by alias",
+			info: ModuleInfo{Name: "ModuleB"},
+			want: ModuleInfo{Name: "ModuleB", BuildFile: config.DefaultBuildFile, Properties: map[string]string{"a": "b"}, DisabledSources: []string{"w"}},
+		},
+		{
+			name: "Should use defaults without override",
+			info: ModuleInfo{Name: "ModuleC"},
+			want: ModuleInfo{Name: "ModuleC", BuildFile: config.DefaultBuildFile},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			err := buildOverrideCalculator(cfg)(&info)
+			if err != nil {
+				t.Errorf("buildOverrideCalculator() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(info, tt.want) {
+				t.Errorf("buildOverrideCalculator() = %v, want %v", info, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SuggestAliases(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		taken map[string]string
+		want  map[string]string
+	}{
+		{
+			name:  "Should use camel case initials",
+			names: []string{"MPMLink", "ProcessPlanBrowser", "Associative"},
+			want:  map[string]string{"mpml": "MPMLink", "ppb": "ProcessPlanBrowser", "ass": "Associative"},
+		},
+		{
+			name:  "Should resolve collisions",
+			names: []string{"MPMLink", "MPMLinkCommon", "MPMLinkCore"},
+			want:  map[string]string{"mpml": "MPMLink", "mpmlc": "MPMLinkCommon", "mpmlco": "MPMLinkCore"},
+		},
+		{
+			name:  "Should skip taken aliases",
+			names: []string{"ProcessPlanBrowser"},
+			taken: map[string]string{"ppb": "Other"},
+			want:  map[string]string{"ppbr": "ProcessPlanBrowser"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SuggestAliases(tt.names, tt.taken); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SuggestAliases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ModuleCandidates(t *testing.T) {
+	cfg := &config.AppConfig{
+		Aliases: map[string]string{"mpml": "MPMLink"},
+		Groups:  map[string][]string{"core": {"mpml"}},
+	}
+	infos := map[string]*ModuleInfo{
+		"MPMLink":            {Name: "MPMLink", Sources: []string{config.SRC, config.SrcWeb}},
+		"ProcessPlanBrowser": {Name: "ProcessPlanBrowser", Sources: []string{config.SRC}, NoClobber: true},
+	}
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{
+			name:   "Should list aliases, modules and sets",
+			prefix: "",
+			want:   []string{"mpml", "MPMLink", "ProcessPlanBrowser", "@all", "@core"},
+		},
+		{
+			name:   "Should filter by prefix",
+			prefix: "@",
+			want:   []string{"@all", "@core"},
+		},
+		{
+			name:   "Should list module source symbols",
+			prefix: "mpml_",
+			want:   []string{"mpml_*", "mpml_c", "mpml_s", "mpml_w"},
+		},
+		{
+			name:   "Should skip used symbols and clobber when not allowed",
+			prefix: "ProcessPlanBrowser_",
+			want:   []string{"ProcessPlanBrowser_*", "ProcessPlanBrowser_s"},
+		},
+		{
+			name:   "Should append remaining symbols",
+			prefix: "mpml_s",
+			want:   []string{"mpml_sc", "mpml_sw"},
+		},
+		{
+			name:   "Should list nothing for unknown module",
+			prefix: "unknown_",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ModuleCandidates(cfg, infos, tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ModuleCandidates() = %v, want %v", got, tt.want)
 			}
 		})
 	}
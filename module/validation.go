@@ -0,0 +1,61 @@
+package module
+
+import (
+	"fmt"
+	"strings"
+	"wnc_builder/config"
+)
+
+func ValidateReferences(cfg *config.AppConfig, infos map[string]*ModuleInfo) config.ValidationErrors {
+	errs := make(config.ValidationErrors, 0)
+	for _, alias := range config.SortedKeys(cfg.Aliases) {
+		name := cfg.Aliases[alias]
+		if infos[name] == nil {
+			path := "aliases." + alias
+			errs = append(errs, config.ValidationError{
+				Origin:  cfg.OriginOf(path),
+				Message: fmt.Sprintf("alias %q points to module %q which is not in the module registry", alias, name),
+			})
+		}
+	}
+	for _, key := range config.SortedKeys(cfg.Modules) {
+		if infos[key] == nil && infos[cfg.Aliases[key]] == nil {
+			errs = append(errs, config.ValidationError{
+				Origin:  cfg.OriginOf("modules." + key),
+				Message: fmt.Sprintf("module override %q matches neither a registry module nor an alias", key),
+			})
+		}
+	}
+	for _, group := range config.SortedKeys(cfg.Groups) {
+		for i, member := range cfg.Groups[group] {
+			if message := validateGroupMember(cfg, infos, member); message != "" {
+				errs = append(errs, config.ValidationError{
+					Origin:  cfg.OriginOf(fmt.Sprintf("groups.%s", group)),
+					Message: fmt.Sprintf("member %d of group %q: %s", i+1, group, message),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+func validateGroupMember(cfg *config.AppConfig, infos map[string]*ModuleInfo, member string) string {
+	if !strings.HasPrefix(member, config.ModuleSetPrefix) {
+		id := strings.Split(member, "_")[0]
+		if infos[id] == nil && infos[cfg.Aliases[id]] == nil {
+			return fmt.Sprintf("module %q is neither a registry module nor an alias", id)
+		}
+		return ""
+	}
+	selector := strings.TrimPrefix(member, config.ModuleSetPrefix)
+	if _, ok := cfg.Groups[selector]; ok {
+		return ""
+	}
+	if idx := strings.LastIndex(selector, "_"); idx >= 0 {
+		selector = selector[:idx]
+	}
+	if _, ok := cfg.Groups[selector]; ok || selector == config.AllModulesSet || strings.HasPrefix(selector, config.RangeModulesSet) {
+		return ""
+	}
+	return fmt.Sprintf("module set %q is not defined", member)
+}
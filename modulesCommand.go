@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+type moduleListing struct {
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases"`
+	Order    int      `json:"order"`
+	Location string   `json:"location"`
+	Exists   bool     `json:"exists"`
+	Sources  []string `json:"sources"`
+}
+
+func runModulesCommand(appConfig *config.AppConfig, moduleInfos map[string]*module.ModuleInfo, command *config.ModulesCommand) error {
+	listings, err := listModules(appConfig, moduleInfos, command)
+	if err != nil {
+		return err
+	}
+	if command.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(listings)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tALIASES\tORDER\tEXISTS\tSOURCES\tLOCATION")
+	for _, listing := range listings {
+		order := "-"
+		if listing.Order >= 0 {
+			order = strconv.Itoa(listing.Order)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%t\t%s\t%s\n", listing.Name, strings.Join(listing.Aliases, ","), order, listing.Exists, strings.Join(listing.Sources, ","), listing.Location)
+	}
+	return writer.Flush()
+}
+
+func listModules(appConfig *config.AppConfig, moduleInfos map[string]*module.ModuleInfo, command *config.ModulesCommand) ([]moduleListing, error) {
+	var pattern *regexp.Regexp
+	if command.Pattern != "" {
+		compiled, err := regexp.Compile(command.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid module name pattern %q. %w", command.Pattern, err)
+		}
+		pattern = compiled
+	}
+
+	aliases := module.ReverseAliases(appConfig)
+	listings := make([]moduleListing, 0, len(moduleInfos))
+	for _, moduleInfo := range moduleInfos {
+		exists := moduleInfo.Exists()
+		switch {
+		case pattern != nil && !pattern.MatchString(moduleInfo.Name):
+			continue
+		case command.Missing && exists:
+			continue
+		case command.Has != "" && !slices.Contains(moduleInfo.Sources, command.Has):
+			continue
+		}
+		listings = append(listings, moduleListing{
+			Name:     moduleInfo.Name,
+			Aliases:  append([]string{}, aliases[moduleInfo.Name]...),
+			Order:    moduleInfo.Order,
+			Location: moduleInfo.Location,
+			Exists:   exists,
+			Sources:  append([]string{}, moduleInfo.Sources...),
+		})
+	}
+	slices.SortFunc(listings, func(a, b moduleListing) int {
+		if (a.Order < 0) != (b.Order < 0) {
+			return b.Order - a.Order
+		}
+		if a.Order != b.Order {
+			return a.Order - b.Order
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return listings, nil
+}
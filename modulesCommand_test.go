@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"wnc_builder/config"
+	"wnc_builder/module"
+)
+
+func Test_listModules(t *testing.T) {
+	root := t.TempDir()
+	existing := filepath.Join(root, "ModuleA")
+	if err := os.Mkdir(existing, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	moduleInfos := map[string]*module.ModuleInfo{
+		"ModuleA":   {Name: "ModuleA", Order: 1, Location: existing, Sources: []string{"src", "src_web"}},
+		"ModuleB":   {Name: "ModuleB", Order: 0, Location: filepath.Join(root, "ModuleB"), Sources: []string{"src"}},
+		"Extra":     {Name: "Extra", Order: -1, Location: filepath.Join(root, "Extra")},
+		"Unordered": {Name: "Unordered", Order: -1, Location: filepath.Join(root, "Unordered"), Sources: []string{"src_web"}},
+	}
+	appConfig := &config.AppConfig{Aliases: map[string]string{"mb": "ModuleB", "b": "ModuleB"}}
+	tests := []struct {
+		name    string
+		command config.ModulesCommand
+		want    []string
+		wantErr bool
+	}{
+		{name: "Should list ordered modules first", want: []string{"ModuleB", "ModuleA", "Extra", "Unordered"}},
+		{name: "Should filter by pattern", command: config.ModulesCommand{Pattern: "^Module"}, want: []string{"ModuleB", "ModuleA"}},
+		{name: "Should filter missing modules", command: config.ModulesCommand{Missing: true}, want: []string{"ModuleB", "Extra", "Unordered"}},
+		{name: "Should filter by source", command: config.ModulesCommand{Has: "src_web"}, want: []string{"ModuleA", "Unordered"}},
+		{name: "Should combine filters", command: config.ModulesCommand{Pattern: "Module", Missing: true, Has: "src"}, want: []string{"ModuleB"}},
+		{name: "Should reject invalid pattern", command: config.ModulesCommand{Pattern: "("}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listings, err := listModules(appConfig, moduleInfos, &tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listModules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := make([]string, 0, len(listings))
+			for _, listing := range listings {
+				got = append(got, listing.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listModules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runModulesCommand_json(t *testing.T) {
+	moduleInfos := map[string]*module.ModuleInfo{
+		"ModuleB": {Name: "ModuleB", Order: 0, Location: "/nonexistent/ModuleB", Sources: []string{"src"}},
+		"Extra":   {Name: "Extra", Order: -1, Location: "/nonexistent/Extra"},
+	}
+	appConfig := &config.AppConfig{Aliases: map[string]string{"mb": "ModuleB", "b": "ModuleB"}}
+
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	outC := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		outC <- buf.String()
+	}()
+	err := runModulesCommand(appConfig, moduleInfos, &config.ModulesCommand{JSON: true})
+	w.Close()
+	os.Stdout = stdout
+	got := <-outC
+
+	want := `[
+  {
+    "name": "ModuleB",
+    "aliases": [
+      "b",
+      "mb"
+    ],
+    "order": 0,
+    "location": "/nonexistent/ModuleB",
+    "exists": false,
+    "sources": [
+      "src"
+    ]
+  },
+  {
+    "name": "Extra",
+    "aliases": [],
+    "order": -1,
+    "location": "/nonexistent/Extra",
+    "exists": false,
+    "sources": []
+  }
+]
+`
+	if err != nil || got != want {
+		t.Errorf("runModulesCommand() = %v %s, want %s", err, got, want)
+	}
+}